@@ -10,6 +10,7 @@ import (
 	"github.com/zparnold/terraform-cost-estimator/api/errors"
 	"github.com/zparnold/terraform-cost-estimator/common/pricers/azure"
 	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strconv"
 )
 
 // Response is of type APIGatewayProxyResponse since we're leveraging the
@@ -32,20 +33,47 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (apiRes
 
 	// Since 'Consumption' is listed as the first item in the PricingScheme const, if a match is not found, Consumption is the default
 	pricingScheme := azure.PricingSchemeLookup[request.QueryStringParameters["pricingScheme"]]
+	opts := azure.PricingOptions{Scheme: pricingScheme}
+	if monthHours, ok := request.QueryStringParameters["monthHours"]; ok {
+		opts.MonthHours, err = strconv.ParseFloat(monthHours, 64)
+		if err != nil || opts.MonthHours <= 0 {
+			apiResp = generateErrorResp(ctx, 400, "Bad Request", fmt.Sprintf("monthHours must be a positive number of hours, got %q", monthHours))
+			err = nil
+			return apiResp, nil
+		}
+	}
+
+	// The body is either the plan on its own, or the plan along with usage and uptime profiles.  A plan never has a
+	// top-level plan key, so a body with one that doesn't decode is a bad request rather than a plan to price.
+	plan := request.Body
+	var topLevel map[string]json.RawMessage
+	if json.Unmarshal([]byte(request.Body), &topLevel) == nil {
+		if _, ok := topLevel["plan"]; ok {
+			var estimateRequest types.EstimateRequest
+			if err := json.Unmarshal([]byte(request.Body), &estimateRequest); err != nil {
+				apiResp = generateErrorResp(ctx, 400, "Bad Request", fmt.Sprintf("could not read the plan, usage and uptime in the request: %v", err))
+				return apiResp, nil
+			}
+			plan = string(estimateRequest.Plan)
+			opts.Usage = estimateRequest.Usage
+			opts.Uptime = estimateRequest.Uptime
+		}
+	}
+	// Profiles that can't be used are the caller's to fix, so they're checked here rather than failing the pricing
+	for _, validate := range []func() error{opts.Uptime.Validate, opts.Usage.Validate} {
+		if err := validate(); err != nil {
+			apiResp = generateErrorResp(ctx, 400, "Bad Request", fmt.Sprintf("%v", err))
+			return apiResp, nil
+		}
+	}
 
-	var r types.ApiResp
 	// TODO: This is hard-coded to the Azure Pricer.  This could be enhanced to dynamically pick the cloud provider (AWS, Azure, GWC)
-	price, unsupportedResources, unestimateableResources, err := azure.PricePlanFile(ctx, request.Body, pricingScheme)
+	r, err := azure.PricePlanFile(ctx, plan, opts)
 	if err != nil {
 		apiResp = generateErrorResp(ctx, 500, "Internal Server Error", fmt.Sprintf("%v", err))
 		err = nil
 		return apiResp, nil
 	}
-	r.TotalEstimate.HourlyCost = price
-	r.TotalEstimate.MonthlyCost = price * types.MONTH_HOURS
-	r.TotalEstimate.YearlyCost = price * types.YEAR_HOURS
-	r.UnsupportedResources = unsupportedResources
-	r.UnestimateableResources = unestimateableResources
 	b, err := json.Marshal(r)
 	if err != nil {
 		apiResp = generateErrorResp(ctx, 500, "Internal Server Error", fmt.Sprintf("%v", err))
@@ -25,6 +25,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"io/ioutil"
 	"os"
+	"text/tabwriter"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -38,8 +39,15 @@ It supports input as either a JSON blob or a standard terraform binary outfile.
 Estimates can be outputted either in table format, JSON, or YAML using the the -o parameter. 
 Possible values are: -o table, -o yaml, -o json
 
+Monthly and yearly estimates assume 730 hours in a month, which can be changed with --month-hours. VMs that are only
+running part of the time can be described in an --uptime-file, and the expected consumption of usage based resources
+such as GB of egress in a --usage-file.  See the README for their formats, or generate a usage file to fill in with
+the usage-template command.
+
 Examples:
 ./tf-estimate plan.out -o table #here plan.out is a file generated by terraform
+./tf-estimate plan.out --uptime-file uptime.yaml --month-hours 744
+./tf-estimate usage-template plan.out > usage.yaml && ./tf-estimate plan.out --usage-file usage.yaml
 
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -71,6 +79,9 @@ Examples:
 }
 var outputFormat string
 var pricingScheme string
+var monthHours float64
+var uptimeFile string
+var usageFile string
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
@@ -85,6 +96,9 @@ func Execute() {
 func init() {
 	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "-o yaml")
 	rootCmd.Flags().StringVarP(&pricingScheme, "scheme", "s", "", "-s reserved1yr")
+	rootCmd.Flags().Float64Var(&monthHours, "month-hours", types.MONTH_HOURS, "--month-hours 744")
+	rootCmd.Flags().StringVar(&uptimeFile, "uptime-file", "", "--uptime-file uptime.yaml")
+	rootCmd.Flags().StringVar(&usageFile, "usage-file", "", "--usage-file usage.yaml")
 }
 
 func executePriceCommand(filepath string) (types.ApiResp, error) {
@@ -92,26 +106,68 @@ func executePriceCommand(filepath string) (types.ApiResp, error) {
 	if err != nil {
 		return types.ApiResp{}, err
 	}
+	if monthHours <= 0 {
+		return types.ApiResp{}, fmt.Errorf("--month-hours must be a positive number of hours, got %g", monthHours)
+	}
 	if pricingScheme == "" {
 		pricingScheme = "consumption"
 	}
-	var r types.ApiResp
-	hourlyPrice, unsupportedResources, unestimableResources, err := azure.PricePlanFile(context.Background(), string(b), azure.PricingSchemeLookup[pricingScheme])
+	opts := azure.PricingOptions{
+		Scheme:     azure.PricingSchemeLookup[pricingScheme],
+		MonthHours: monthHours,
+	}
+	if uptimeFile != "" {
+		if err := readProfile(uptimeFile, &opts.Uptime); err != nil {
+			return types.ApiResp{}, err
+		}
+	}
+	if usageFile != "" {
+		if err := readProfile(usageFile, &opts.Usage); err != nil {
+			return types.ApiResp{}, err
+		}
+	}
+	return azure.PricePlanFile(context.Background(), string(b), opts)
+}
+
+// readProfile reads an uptime or usage file, which can be either YAML or JSON since JSON is valid YAML
+func readProfile(filepath string, profile interface{}) error {
+	b, err := ioutil.ReadFile(filepath)
 	if err != nil {
-		return types.ApiResp{}, err
+		return err
+	}
+	if err := yaml.Unmarshal(b, profile); err != nil {
+		return fmt.Errorf("could not read %s: %v", filepath, err)
 	}
-	r.UnsupportedResources = unsupportedResources
-	r.UnestimateableResources = unestimableResources
-	r.TotalEstimate.HourlyCost = hourlyPrice
-	r.TotalEstimate.MonthlyCost = hourlyPrice * types.MONTH_HOURS
-	r.TotalEstimate.YearlyCost = hourlyPrice * types.YEAR_HOURS
-	return r, nil
+	return nil
 }
 
 func output(t types.ApiResp) {
-	fmt.Printf("Hourly Estimate: $%0.2f\n", t.TotalEstimate.HourlyCost)
-	fmt.Printf("Monthly Estimate: $%0.2f\n", t.TotalEstimate.MonthlyCost)
-	fmt.Printf("Yearly Estimate: $%0.2f\n", t.TotalEstimate.YearlyCost)
+	fmt.Printf("Hourly Estimate: %s\n", formatSpread(t.TotalEstimate.MinHourlyCost, t.TotalEstimate.HourlyCost, t.TotalEstimate.MaxHourlyCost))
+	fmt.Printf("Monthly Estimate: %s\n", formatSpread(t.TotalEstimate.MinMonthlyCost, t.TotalEstimate.MonthlyCost, t.TotalEstimate.MaxMonthlyCost))
+	fmt.Printf("Yearly Estimate: %s\n", formatSpread(t.TotalEstimate.MinYearlyCost, t.TotalEstimate.YearlyCost, t.TotalEstimate.MaxYearlyCost))
+	if len(t.PriceItems) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RESOURCE\tMIN MONTHLY\tEXPECTED MONTHLY\tMAX MONTHLY")
+		for _, item := range t.PriceItems {
+			fmt.Fprintf(w, "%s\t$%0.2f\t$%0.2f\t$%0.2f\n", item.Address, item.Estimate.MinMonthlyCost, item.Estimate.MonthlyCost, item.Estimate.MaxMonthlyCost)
+		}
+		_ = w.Flush()
+		fmt.Println()
+		for _, item := range t.PriceItems {
+			for _, n := range item.Notes {
+				fmt.Printf("Note: %s: %s\n", item.Address, n)
+			}
+		}
+	}
 	fmt.Println("Unsupported Resources:", t.UnsupportedResources)
 	fmt.Println("Unestimateable Resources:", t.UnestimateableResources)
 }
+
+// formatSpread only shows the low and high estimate when they differ from the expected one
+func formatSpread(min, expected, max float64) string {
+	if min == expected && max == expected {
+		return fmt.Sprintf("$%0.2f", expected)
+	}
+	return fmt.Sprintf("$%0.2f (min $%0.2f, max $%0.2f)", expected, min, max)
+}
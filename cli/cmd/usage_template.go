@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/zparnold/terraform-cost-estimator/common/pricers/azure"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// usageTemplateCmd generates a usage file for a plan
+var usageTemplateCmd = &cobra.Command{
+	Use:   "usage-template",
+	Short: "Generates a usage file to fill in for the usage based resources in a terraform plan",
+	Long: `usage-template reads a terraform plan and writes out a usage file listing every usage based resource in it, with
+each of the quantities its price depends on commented out.  Uncomment and fill in the expected monthly quantities and
+pass the file back in with --usage-file, the quantities left commented out aren't priced.
+
+Examples:
+./tf-estimate usage-template plan.out > usage.yaml
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath := args[0]
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			return fmt.Errorf("%v, try the command again with a valid filepath", err)
+		}
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		template, err := azure.UsageTemplate(string(b))
+		if err != nil {
+			return err
+		}
+		o, err := usageTemplateYaml(template)
+		if err != nil {
+			return err
+		}
+		fmt.Print(usageTemplateHeader + o)
+		return nil
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+// usageTemplateHeader is written at the top of a usage template to explain how to fill it in
+const usageTemplateHeader = `# Expected monthly quantities for the usage based resources in the plan.  Uncomment a quantity and set it to have it
+# priced, even a zero is priced once it's uncommented.
+`
+
+// usageTemplateYaml writes a usage file with the usage keys of each resource commented out, so that passing it back in
+// unedited doesn't change the estimate
+func usageTemplateYaml(template map[string][]string) (string, error) {
+	var addresses []string
+	for address := range template {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	var b strings.Builder
+	b.WriteString("resources:\n")
+	for _, address := range addresses {
+		key, err := yaml.Marshal(address)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "  %s:\n", strings.TrimSpace(string(key)))
+		for _, usageKey := range template[address] {
+			fmt.Fprintf(&b, "    # %s: 0\n", usageKey)
+		}
+	}
+	return b.String(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(usageTemplateCmd)
+}
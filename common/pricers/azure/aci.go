@@ -0,0 +1,58 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"math"
+	"sort"
+)
+
+/*
+containerGroupPricers prices an azurerm_container_group for the vCPUs, memory and GPUs of its containers, plus the
+Windows software charge per vCPU of a Windows group.  Container groups are billed per second while they run, which is
+assumed to be the whole month unless its running_hours usage, or failing that an uptime profile, says otherwise.
+*/
+func containerGroupPricers(values map[string]interface{}, usage types.Usage, monthHours float64) []types.Priceable {
+	var cpu, memory float64
+	gpus := map[string]float64{}
+	for _, container := range getBlocks(values, "container") {
+		c, _ := getFloat(container, "cpu")
+		m, _ := getFloat(container, "memory")
+		cpu, memory = cpu+c, memory+m
+		if gpu := getBlock(container, "gpu"); gpu != nil {
+			count, _ := getFloat(gpu, "count")
+			gpus[getString(gpu, "sku")] += count
+		}
+	}
+	filter := fmt.Sprintf("serviceName eq 'Container Instances' and armRegionName eq '%s'", getString(values, "location"))
+	meters := []*meter{
+		hourlyMeter(filter+" and skuName eq 'Standard' and (contains(meterName,'vCPU Duration') eq true)", cpu),
+		hourlyMeter(filter+" and skuName eq 'Standard' and (contains(meterName,'Memory Duration') eq true)", memory),
+	}
+	if getString(values, "os_type") == "Windows" {
+		meters = append(meters, hourlyMeter(filter+" and (contains(meterName,'Windows Software Duration') eq true)", cpu))
+	}
+	var skus []string
+	for sku := range gpus {
+		skus = append(skus, sku)
+	}
+	sort.Strings(skus)
+	for _, sku := range skus {
+		meters = append(meters, hourlyMeter(filter+fmt.Sprintf(" and skuName eq '%s' and (contains(meterName,'vGPU Duration') eq true)", sku), gpus[sku]))
+	}
+
+	var pricers []types.Priceable
+	if hours, ok := usage.Get("running_hours"); ok && monthHours > 0 {
+		//Plain meters rather than scheduled ones, so that an uptime profile can't override the usage
+		fraction := math.Min(hours/monthHours, 1)
+		for _, m := range meters {
+			m.Quantity = m.Quantity.Scale(fraction)
+			pricers = append(pricers, m)
+		}
+		return pricers
+	}
+	for _, m := range meters {
+		pricers = append(pricers, &scheduledMeter{meter: m})
+	}
+	return pricers
+}
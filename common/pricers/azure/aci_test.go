@@ -0,0 +1,46 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestContainerGroupRunningHours(t *testing.T) {
+	values := map[string]interface{}{
+		"location": "eastus",
+		"os_type":  "Linux",
+		"container": []interface{}{
+			map[string]interface{}{"cpu": 1.0, "memory": 1.5},
+			map[string]interface{}{"cpu": 0.5, "memory": 0.5},
+		},
+	}
+	cases := []struct {
+		usage types.Usage
+		// the vCPUs and GB of memory billed, as a share of the month when running_hours says so
+		cpu, memory float64
+		scheduled   bool
+	}{
+		{types.Usage{}, 1.5, 2, true},
+		{types.Usage{"running_hours": 365}, 0.75, 1, false},
+		{types.Usage{"running_hours": 1000}, 1.5, 2, false},
+	}
+	for _, c := range cases {
+		pricers := containerGroupPricers(values, c.usage, 730)
+		if !assert.Len(t, pricers, 2, "%v", c.usage) {
+			continue
+		}
+		for i, quantity := range []float64{c.cpu, c.memory} {
+			// an uptime profile can only apply to the group when its usage doesn't already say how long it runs
+			_, scheduled := pricers[i].(scheduledPricer)
+			assert.Equal(t, c.scheduled, scheduled, "%v", c.usage)
+			var m *meter
+			if s, ok := pricers[i].(*scheduledMeter); ok {
+				m = s.meter
+			} else {
+				m = pricers[i].(*meter)
+			}
+			assert.InDelta(t, quantity, m.Quantity.Expected, 0.0001, "%v", c.usage)
+		}
+	}
+}
@@ -1,14 +1,84 @@
 package azure
 
-import "context"
+import (
+	"context"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+)
+
+// AKS doesn't bill for the OS disk size, only the disk tier it lands in, so this matches the provider's default
+const DEFAULT_AKS_OS_DISK_GB = 128.0
+
+// Hourly price of the control plane by sku_tier.  "Paid" is the name older provider versions use for "Standard".
+var aksControlPlanePrices = map[string]float64{
+	"Free":     0.0,
+	"Paid":     0.10,
+	"Standard": 0.10,
+	"Premium":  0.60,
+}
 
 type AksCluster struct {
-	IsPaid bool
+	SkuTier string
+}
+
+func (A *AksCluster) GetHourlyPrice(context.Context) types.Range {
+	return types.FixedRange(aksControlPlanePrices[A.SkuTier])
 }
 
-func (A *AksCluster) GetHourlyPrice(context.Context) float64 {
-	if A.IsPaid {
-		return 0.10
+// aksNodePoolPricers prices the nodes and managed OS disks of either a default_node_pool block or an
+// azurerm_kubernetes_cluster_node_pool.  Autoscaling pools are expected to run at their current node count (or
+// min_count when that isn't known) and are priced over the min_count to max_count range they can scale between.
+func aksNodePoolPricers(pool map[string]interface{}, location string, priceType PricingScheme) []types.Priceable {
+	size := getString(pool, "vm_size")
+	count, hasCount := getFloat(pool, "node_count")
+	minCount, maxCount := count, count
+	//enable_auto_scaling was renamed to auto_scaling_enabled in v4 of the provider
+	if getBool(pool, "enable_auto_scaling") || getBool(pool, "auto_scaling_enabled") {
+		minCount, _ = getFloat(pool, "min_count")
+		maxCount, _ = getFloat(pool, "max_count")
+		if !hasCount {
+			count = minCount
+		}
 	}
-	return 0.0
+
+	pricers := []types.Priceable{&VirtualMachine{
+		Size:          size,
+		Location:      location,
+		Count:         count,
+		MinCount:      minCount,
+		MaxCount:      maxCount,
+		IsWindows:     getString(pool, "os_type") == "Windows",
+		IsSpotEnabled: getString(pool, "priority") == "Spot",
+		PricingScheme: priceType,
+	}}
+
+	//Ephemeral OS disks live on the VM's cache/temp disk and aren't billed separately
+	if getString(pool, "os_disk_type") != "Ephemeral" {
+		diskSize, ok := getFloat(pool, "os_disk_size_gb")
+		if !ok || diskSize == 0 {
+			diskSize = DEFAULT_AKS_OS_DISK_GB
+		}
+		diskSku := "StandardSSD_LRS"
+		if supportsPremiumStorage(size) {
+			diskSku = "Premium_LRS"
+		}
+		pricers = append(pricers, &AzureDisk{
+			Location: location,
+			SizeInGb: diskSize,
+			SkuTier:  diskSku,
+			Count:    int(count),
+			MinCount: int(minCount),
+			MaxCount: int(maxCount),
+		})
+	}
+	return pricers
+}
+
+// supportsPremiumStorage guesses from the VM size name whether it can attach premium SSDs, which AKS uses for managed
+// OS disks when it can, e.g. Standard_DS2_v2 and Standard_D4s_v3 can but Standard_D2_v2 can't
+func supportsPremiumStorage(size string) bool {
+	name := strings.SplitN(strings.TrimPrefix(size, "Standard_"), "_", 2)[0]
+	family := strings.TrimRight(name, "0123456789abcdefghijklmnopqrstuvwxyz-")
+	capabilities := strings.TrimLeft(strings.TrimPrefix(name, family), "0123456789-")
+	return strings.HasSuffix(family, "S") || strings.Contains(capabilities, "s")
 }
@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strconv"
+	"strings"
+)
+
+// API Management tiers with units, and how the pricing API's sku names spell them
+var apiManagementTiers = map[string]string{
+	"Developer":  "Developer",
+	"Basic":      "Basic",
+	"Standard":   "Standard",
+	"Premium":    "Premium",
+	"BasicV2":    "Basic v2",
+	"StandardV2": "Standard v2",
+	"PremiumV2":  "Premium v2",
+}
+
+// apiManagementPricers prices an API Management service from its sku_name, a tier and a number of units such as
+// Premium_2, by the hour in its own region and in each additional_location with that location's capacity.
+// Consumption services have no units and are priced per call from their usage instead.  A tier that isn't known, such
+// as Isolated, isn't priced.
+func apiManagementPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	parts := strings.SplitN(getString(values, "sku_name"), "_", 2)
+	tier := parts[0]
+	location := getString(values, "location")
+	if tier == "Consumption" {
+		//The first million calls a month are free, which is the bottom tier of this meter
+		calls := tieredMeter(usageMeter(usage, "calls", fmt.Sprintf("serviceName eq 'API Management' and armRegionName eq '%s' and skuName eq 'Consumption' and (contains(meterName,'Calls') eq true)", location)))
+		if calls == nil {
+			return []types.Priceable{note("no calls usage given for this Consumption service, add calls to a usage profile to price it")}
+		}
+		return []types.Priceable{calls}
+	}
+
+	skuName, ok := apiManagementTiers[tier]
+	if !ok {
+		return nil
+	}
+	units := 1.0
+	if len(parts) == 2 {
+		if capacity, err := strconv.ParseFloat(parts[1], 64); err == nil {
+			units = capacity
+		}
+	}
+	unitMeter := func(location string, units float64) *meter {
+		return hourlyMeter(fmt.Sprintf("serviceName eq 'API Management' and armRegionName eq '%s' and skuName eq '%s' and (contains(meterName,'Unit') eq true)", location, skuName), units)
+	}
+	pricers := []types.Priceable{unitMeter(location, units)}
+	for _, additional := range getBlocks(values, "additional_location") {
+		capacity, ok := getFloat(additional, "capacity")
+		if !ok || capacity < 1 {
+			capacity = 1
+		}
+		pricers = append(pricers, unitMeter(getString(additional, "location"), capacity))
+	}
+	return pricers
+}
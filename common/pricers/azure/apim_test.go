@@ -0,0 +1,69 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestApiManagementUnits(t *testing.T) {
+	type unit struct {
+		location string
+		units    float64
+	}
+	cases := []struct {
+		values  map[string]interface{}
+		skuName string
+		units   []unit
+	}{
+		{map[string]interface{}{"sku_name": "Developer_1"}, "Developer", []unit{{"eastus", 1}}},
+		{map[string]interface{}{"sku_name": "Standard_2"}, "Standard", []unit{{"eastus", 2}}},
+		{map[string]interface{}{"sku_name": "Premium"}, "Premium", []unit{{"eastus", 1}}},
+		{map[string]interface{}{"sku_name": "BasicV2_1"}, "Basic v2", []unit{{"eastus", 1}}},
+		{map[string]interface{}{"sku_name": "StandardV2_3"}, "Standard v2", []unit{{"eastus", 3}}},
+		{map[string]interface{}{"sku_name": "Premium_3", "additional_location": []interface{}{
+			map[string]interface{}{"location": "westeurope", "capacity": 2.0},
+			map[string]interface{}{"location": "japaneast"},
+		}}, "Premium", []unit{{"eastus", 3}, {"westeurope", 2}, {"japaneast", 1}}},
+	}
+	for _, c := range cases {
+		c.values["location"] = "eastus"
+		pricers := apiManagementPricers(c.values, types.Usage{})
+		if !assert.Len(t, pricers, len(c.units), "%v", c.values) {
+			continue
+		}
+		for i, u := range c.units {
+			m := pricers[i].(*meter)
+			assert.Contains(t, m.Filter, "armRegionName eq '"+u.location+"'", "%v", c.values)
+			assert.Contains(t, m.Filter, "skuName eq '"+c.skuName+"'", "%v", c.values)
+			assert.Equal(t, types.FixedRange(u.units), m.Quantity, "%v", c.values)
+		}
+	}
+}
+
+func TestApiManagementUnpricedTiers(t *testing.T) {
+	cases := []struct {
+		skuName string
+		usage   types.Usage
+		meter   bool
+	}{
+		{"Consumption_0", types.Usage{"calls": 5000000}, true},
+		{"Consumption_0", types.Usage{}, false},
+	}
+	for _, c := range cases {
+		pricers := apiManagementPricers(map[string]interface{}{"sku_name": c.skuName, "location": "eastus"}, c.usage)
+		if !assert.Len(t, pricers, 1, c.skuName) {
+			continue
+		}
+		if c.meter {
+			assert.True(t, pricers[0].(*meter).Tiered, c.skuName)
+		} else {
+			assert.IsType(t, note(""), pricers[0], c.skuName)
+		}
+	}
+
+	// tiers that aren't known are left for the plan to list as unsupported
+	for _, skuName := range []string{"Isolated_1", ""} {
+		assert.Empty(t, apiManagementPricers(map[string]interface{}{"sku_name": skuName, "location": "eastus"}, types.Usage{}), skuName)
+	}
+}
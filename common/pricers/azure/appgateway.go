@@ -0,0 +1,102 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+)
+
+// A v2 gateway instance handles up to 10 capacity units, so that many are billed for every instance it's scaled to
+const APP_GATEWAY_CAPACITY_UNITS_PER_INSTANCE = 10.0
+
+/*
+ApplicationGateway prices an azurerm_application_gateway.  v1 gateways are billed per instance of their size, plus the
+data they process.  v2 gateways are billed a fixed hourly fee plus the capacity units they use, at least the capacity
+of their minimum instances and at most that of their maximum instances, with the expected usage coming from the
+capacity_units usage.
+*/
+type ApplicationGateway struct {
+	Location string
+	//Standard, WAF, Standard_v2 or WAF_v2
+	Tier      string
+	Size      string
+	Instances types.Range
+}
+
+func newApplicationGateway(values map[string]interface{}) *ApplicationGateway {
+	sku := getBlock(values, "sku")
+	g := &ApplicationGateway{
+		Location: getString(values, "location"),
+		Tier:     getString(sku, "tier"),
+	}
+	//v1 sizes are named for their tier, e.g. Standard_Medium or WAF_Large
+	if parts := strings.SplitN(getString(sku, "name"), "_", 2); len(parts) == 2 {
+		g.Size = parts[1]
+	}
+	capacity, ok := getFloat(sku, "capacity")
+	if !ok {
+		capacity = 1
+	}
+	g.Instances = types.FixedRange(capacity)
+	if autoscale := getBlock(values, "autoscale_configuration"); autoscale != nil {
+		min, _ := getFloat(autoscale, "min_capacity")
+		max, ok := getFloat(autoscale, "max_capacity")
+		if !ok || max < min {
+			max = min
+		}
+		g.Instances = types.Range{Min: min, Expected: min, Max: max}
+	}
+	return g
+}
+
+func (g *ApplicationGateway) isV2() bool {
+	return strings.HasSuffix(g.Tier, "_v2")
+}
+
+func (g *ApplicationGateway) pricers(usage types.Usage) []types.Priceable {
+	if !g.isV2() {
+		product := fmt.Sprintf("serviceName eq 'Application Gateway' and armRegionName eq '%s' and productName eq 'Application Gateway %s'", g.Location, g.Tier)
+		return appendMeters([]types.Priceable{&meter{
+			Filter:   product + fmt.Sprintf(" and skuName eq '%s' and (contains(meterName,'Gateway') eq true)", g.Size),
+			Quantity: g.Instances,
+			Period:   perHour,
+		}}, tieredMeter(usageMeter(usage, "data_processed_gb", product+fmt.Sprintf(" and skuName eq '%s' and (contains(meterName,'Data Processed') eq true)", g.Size))))
+	}
+
+	//Standard_v2 meters are named Standard and WAF_v2 meters are named WAF
+	name := strings.TrimSuffix(g.Tier, "_v2")
+	product := fmt.Sprintf("serviceName eq 'Application Gateway' and armRegionName eq '%s' and productName eq 'Application Gateway %s v2'", g.Location, name)
+	units := g.Instances.Scale(APP_GATEWAY_CAPACITY_UNITS_PER_INSTANCE)
+	if used, ok := usage.Get("capacity_units"); ok && g.Instances.Min != g.Instances.Max {
+		units.Expected = used
+		if units.Expected < units.Min {
+			units.Expected = units.Min
+		} else if units.Expected > units.Max {
+			units.Expected = units.Max
+		}
+	}
+	return []types.Priceable{
+		hourlyMeter(product+fmt.Sprintf(" and meterName eq '%s Fixed Cost'", name), 1),
+		&meter{Filter: product + fmt.Sprintf(" and meterName eq '%s Capacity Units'", name), Quantity: units, Period: perHour},
+	}
+}
+
+// Attributes an azurerm_application_gateway can attach an azurerm_web_application_firewall_policy with
+var wafPolicyAttributes = []string{"firewall_policy_id", "http_listener.firewall_policy_id", "url_path_map.path_rule.firewall_policy_id"}
+
+// wafPolicyGateways returns the application gateways a firewall policy is attached to
+func wafPolicyGateways(graph *planGraph, res *planResource) []*planResource {
+	var gateways []*planResource
+	for _, attribute := range wafPolicyAttributes {
+		for _, gateway := range graph.referencing(res, "azurerm_application_gateway", attribute) {
+			found := false
+			for _, g := range gateways {
+				found = found || g == gateway
+			}
+			if !found {
+				gateways = append(gateways, gateway)
+			}
+		}
+	}
+	return gateways
+}
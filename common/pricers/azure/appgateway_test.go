@@ -0,0 +1,52 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestApplicationGatewayCapacity(t *testing.T) {
+	gateway := func(tier, name string, sku map[string]interface{}, autoscale map[string]interface{}) map[string]interface{} {
+		sku["tier"], sku["name"] = tier, name
+		values := map[string]interface{}{"location": "eastus", "sku": []interface{}{sku}}
+		if autoscale != nil {
+			values["autoscale_configuration"] = []interface{}{autoscale}
+		}
+		return values
+	}
+	cases := []struct {
+		name      string
+		values    map[string]interface{}
+		size      string
+		instances types.Range
+	}{
+		{"v1 with capacity", gateway("Standard", "Standard_Medium", map[string]interface{}{"capacity": 2.0}, nil), "Medium", types.FixedRange(2)},
+		{"v1 without capacity", gateway("WAF", "WAF_Large", map[string]interface{}{}, nil), "Large", types.FixedRange(1)},
+		{"v2 autoscale", gateway("Standard_v2", "Standard_v2", map[string]interface{}{}, map[string]interface{}{"min_capacity": 2.0, "max_capacity": 10.0}), "v2", types.Range{Min: 2, Expected: 2, Max: 10}},
+		{"v2 autoscale without max", gateway("WAF_v2", "WAF_v2", map[string]interface{}{}, map[string]interface{}{"min_capacity": 3.0}), "v2", types.FixedRange(3)},
+	}
+	for _, c := range cases {
+		g := newApplicationGateway(c.values)
+		assert.Equal(t, c.size, g.Size, c.name)
+		assert.Equal(t, c.instances, g.Instances, c.name)
+	}
+}
+
+func TestApplicationGatewayCapacityUnits(t *testing.T) {
+	g := &ApplicationGateway{Location: "eastus", Tier: "WAF_v2", Instances: types.Range{Min: 2, Expected: 2, Max: 10}}
+	cases := []struct {
+		usage types.Usage
+		units types.Range
+	}{
+		{types.Usage{}, types.Range{Min: 20, Expected: 20, Max: 100}},
+		{types.Usage{"capacity_units": 45}, types.Range{Min: 20, Expected: 45, Max: 100}},
+		{types.Usage{"capacity_units": 5}, types.Range{Min: 20, Expected: 20, Max: 100}},
+		{types.Usage{"capacity_units": 500}, types.Range{Min: 20, Expected: 100, Max: 100}},
+	}
+	for _, c := range cases {
+		pricers := g.pricers(c.usage)
+		assert.Equal(t, types.FixedRange(1), pricers[0].(*meter).Quantity, "%v", c.usage)
+		assert.Equal(t, c.units, pricers[1].(*meter).Quantity, "%v", c.usage)
+	}
+}
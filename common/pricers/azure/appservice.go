@@ -0,0 +1,164 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"regexp"
+	"strings"
+)
+
+// Elastic Premium plans are billed on the vCPU and memory of their instances rather than per instance
+var elasticPremiumSizes = map[string]struct{ Cores, MemoryGb float64 }{
+	"EP1": {1, 3.5},
+	"EP2": {2, 7},
+	"EP3": {4, 14},
+}
+
+// The pricing API separates the generation of a size with a space, e.g. P1v3 is "P1 v3"
+var appServiceSkuGenerationRegex = regexp.MustCompile(`^(\w+?)(v\d)$`)
+
+// App and function app types that run on an App Service plan, with the attributes that link them to it
+var appServicePlanLinks = map[string][]string{
+	"azurerm_app_service":          {"app_service_plan_id"},
+	"azurerm_app_service_slot":     {"app_service_plan_id"},
+	"azurerm_function_app":         {"app_service_plan_id"},
+	"azurerm_function_app_slot":    {"app_service_plan_id"},
+	"azurerm_linux_web_app":        {"service_plan_id"},
+	"azurerm_windows_web_app":      {"service_plan_id"},
+	"azurerm_linux_function_app":   {"service_plan_id"},
+	"azurerm_windows_function_app": {"service_plan_id"},
+	"azurerm_linux_web_app_slot":   {"service_plan_id", "app_service_id"},
+	"azurerm_windows_web_app_slot": {"service_plan_id", "app_service_id"},
+}
+
+type AppServicePlan struct {
+	Location string
+	//The size of the plan's instances, e.g. P1v3 or EP1
+	Sku       string
+	IsLinux   bool
+	Instances types.Range
+}
+
+// newAppServicePlan reads either the legacy azurerm_app_service_plan or its replacement azurerm_service_plan
+func newAppServicePlan(graph *planGraph, res *planResource) *AppServicePlan {
+	p := &AppServicePlan{Location: getString(res.Values, "location")}
+	var workers float64
+	if res.Type == "azurerm_app_service_plan" {
+		sku := getBlock(res.Values, "sku")
+		p.Sku = getString(sku, "size")
+		workers, _ = getFloat(sku, "capacity")
+		kind := strings.ToLower(getString(res.Values, "kind"))
+		p.IsLinux = getBool(res.Values, "reserved") || kind == "linux"
+	} else {
+		p.Sku = getString(res.Values, "sku_name")
+		workers, _ = getFloat(res.Values, "worker_count")
+		p.IsLinux = getString(res.Values, "os_type") == "Linux"
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	p.Instances = types.FixedRange(workers)
+	if min, expected, max, ok := linkedAutoscaleCapacity(graph, res); ok {
+		p.Instances = types.Range{Min: min, Expected: expected, Max: max}
+	} else if p.isElasticPremium() {
+		//Elastic Premium plans scale out on their own, from the instances kept ready for their function apps up to the
+		//maximum burst
+		p.Instances = types.FixedRange(elasticPremiumReadyInstances(graph, res, workers))
+		if maxElastic, ok := getFloat(res.Values, "maximum_elastic_worker_count"); ok && maxElastic > p.Instances.Max {
+			p.Instances.Max = maxElastic
+		}
+	}
+	return p
+}
+
+// elasticPremiumReadyInstances is the number of instances an Elastic Premium plan always runs, the most always-ready
+// instances of the plan or any of its function apps, plus the pre-warmed instances kept as a buffer on top of them
+func elasticPremiumReadyInstances(graph *planGraph, plan *planResource, workers float64) float64 {
+	ready, prewarmed := workers, 0.0
+	for appType, attributes := range appServicePlanLinks {
+		if !strings.HasSuffix(appType, "function_app") {
+			continue
+		}
+		for _, app := range graph.referencing(plan, appType, attributes[0]) {
+			siteConfig := getBlock(app.Values, "site_config")
+			if minimum, _ := getFloat(siteConfig, "elastic_instance_minimum"); minimum > ready {
+				ready = minimum
+			}
+			if warm, _ := getFloat(siteConfig, "pre_warmed_instance_count"); warm > prewarmed {
+				prewarmed = warm
+			}
+		}
+	}
+	return ready + prewarmed
+}
+
+// isConsumption is true for plans that cost nothing themselves, with their function apps billed per execution
+func (p *AppServicePlan) isConsumption() bool {
+	return p.Sku == "Y1" || p.Sku == "FC1"
+}
+
+func (p *AppServicePlan) isElasticPremium() bool {
+	_, ok := elasticPremiumSizes[p.Sku]
+	return ok
+}
+
+func (p *AppServicePlan) pricers() []types.Priceable {
+	if p.isConsumption() {
+		return []types.Priceable{note("consumption plans are billed through the executions of their function apps")}
+	}
+	if size, ok := elasticPremiumSizes[p.Sku]; ok {
+		filter := fmt.Sprintf("serviceName eq 'Functions' and armRegionName eq '%s' and skuName eq 'Premium'", p.Location)
+		return []types.Priceable{
+			&meter{Filter: filter + " and meterName eq 'Premium vCPU Duration'", Quantity: p.Instances.Scale(size.Cores), Period: perHour},
+			&meter{Filter: filter + " and meterName eq 'Premium Memory Duration'", Quantity: p.Instances.Scale(size.MemoryGb), Period: perHour},
+		}
+	}
+	//Isolated plans run in an App Service Environment and are priced per instance like any other dedicated plan
+	sku := appServiceSkuGenerationRegex.ReplaceAllString(p.Sku, "$1 $2")
+	return []types.Priceable{&meter{
+		Filter: fmt.Sprintf("serviceName eq 'Azure App Service' and armRegionName eq '%s' and skuName eq '%s' and (contains(productName,'Linux') eq %t)",
+			p.Location, sku, p.IsLinux),
+		Quantity: p.Instances,
+		Period:   perHour,
+	}}
+}
+
+// appServicePlanFor returns the plan an app or function app runs on, following a slot through its app when the slot
+// doesn't name a plan of its own
+func appServicePlanFor(graph *planGraph, res *planResource) *planResource {
+	for _, attribute := range appServicePlanLinks[res.Type] {
+		for _, linked := range graph.referencedBy(res, attribute) {
+			switch linked.Type {
+			case "azurerm_app_service_plan", "azurerm_service_plan":
+				return linked
+			}
+			if _, ok := appServicePlanLinks[linked.Type]; ok && linked != res {
+				if plan := appServicePlanFor(graph, linked); plan != nil {
+					return plan
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// functionAppConsumptionPricers prices a function app on a consumption plan from the executions and GB-seconds of
+// execution time in its usage.  The monthly free grant is the bottom tier of each meter and is shared by every function
+// app in a subscription, so the usage already priced for other apps is passed in used and taken off the grant first.
+func functionAppConsumptionPricers(location string, usage types.Usage, used map[string]float64) []types.Priceable {
+	filter := fmt.Sprintf("serviceName eq 'Functions' and armRegionName eq '%s' and skuName eq 'Standard'", location)
+	var pricers []types.Priceable
+	for _, charge := range []struct{ key, meterName string }{{"executions", "Executions"}, {"gb_seconds", "Execution Time"}} {
+		m := tieredMeter(usageMeter(usage, charge.key, filter+fmt.Sprintf(" and (contains(meterName,'%s') eq true)", charge.meterName)))
+		if m == nil {
+			continue
+		}
+		m.TierOffset = used[charge.key]
+		used[charge.key] += m.Quantity.Expected
+		pricers = append(pricers, m)
+	}
+	if len(pricers) == 0 {
+		return []types.Priceable{note("no usage given for this function app, add executions and gb_seconds to a usage profile to price it")}
+	}
+	return pricers
+}
@@ -0,0 +1,114 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestAppServicePlanSku(t *testing.T) {
+	cases := []struct {
+		address string
+		values  map[string]interface{}
+		sku     string
+		linux   bool
+		workers float64
+	}{
+		{"azurerm_service_plan.p", map[string]interface{}{"sku_name": "P1v3", "os_type": "Linux", "worker_count": 3.0}, "P1 v3", true, 3},
+		{"azurerm_service_plan.p", map[string]interface{}{"sku_name": "P2v2", "os_type": "Windows"}, "P2 v2", false, 1},
+		{"azurerm_service_plan.p", map[string]interface{}{"sku_name": "S1", "os_type": "Windows", "worker_count": 2.0}, "S1", false, 2},
+		{"azurerm_service_plan.p", map[string]interface{}{"sku_name": "I1v2", "os_type": "Linux"}, "I1 v2", true, 1},
+		{"azurerm_service_plan.p", map[string]interface{}{"sku_name": "B1", "os_type": "Linux", "worker_count": 0.0}, "B1", true, 1},
+		{"azurerm_app_service_plan.p", map[string]interface{}{"kind": "Linux", "sku": []interface{}{map[string]interface{}{"size": "P1v3", "capacity": 2.0}}}, "P1 v3", true, 2},
+		{"azurerm_app_service_plan.p", map[string]interface{}{"kind": "app", "reserved": true, "sku": []interface{}{map[string]interface{}{"size": "S2"}}}, "S2", true, 1},
+		{"azurerm_app_service_plan.p", map[string]interface{}{"kind": "Windows", "sku": []interface{}{map[string]interface{}{"size": "S2", "capacity": 4.0}}}, "S2", false, 4},
+	}
+	for _, c := range cases {
+		c.values["location"] = "eastus"
+		res := testResource(c.address, c.values, nil)
+		pricers := newAppServicePlan(testGraph(res), res).pricers()
+		if !assert.Len(t, pricers, 1, "%v", c.values) {
+			continue
+		}
+		m := pricers[0].(*meter)
+		assert.Contains(t, m.Filter, "skuName eq '"+c.sku+"'", "%v", c.values)
+		if c.linux {
+			assert.Contains(t, m.Filter, "(contains(productName,'Linux') eq true)", "%v", c.values)
+		} else {
+			assert.Contains(t, m.Filter, "(contains(productName,'Linux') eq false)", "%v", c.values)
+		}
+		assert.Equal(t, types.FixedRange(c.workers), m.Quantity, "%v", c.values)
+	}
+}
+
+func TestAppServicePlanConsumption(t *testing.T) {
+	cases := []struct {
+		sku         string
+		consumption bool
+	}{
+		{"Y1", true},
+		{"FC1", true},
+		{"EP1", false},
+		{"B1", false},
+		{"P1v3", false},
+	}
+	for _, c := range cases {
+		res := testResource("azurerm_service_plan.p", map[string]interface{}{"sku_name": c.sku, "location": "eastus"}, nil)
+		plan := newAppServicePlan(testGraph(res), res)
+		assert.Equal(t, c.consumption, plan.isConsumption(), c.sku)
+		if c.consumption {
+			pricers := plan.pricers()
+			if assert.Len(t, pricers, 1, c.sku) {
+				assert.IsType(t, note(""), pricers[0], c.sku)
+			}
+		}
+	}
+}
+
+func TestElasticPremiumInstances(t *testing.T) {
+	planRef := map[string][]string{"service_plan_id": {"azurerm_service_plan.ep"}}
+	cases := []struct {
+		name      string
+		sku       string
+		plan      map[string]interface{}
+		apps      []*planResource
+		instances types.Range
+	}{
+		{"workers only", "EP1", map[string]interface{}{"worker_count": 2.0}, nil, types.FixedRange(2)},
+		{"always ready and pre-warmed instances of an app", "EP2", map[string]interface{}{"worker_count": 1.0}, []*planResource{
+			testResource("azurerm_linux_function_app.a", map[string]interface{}{"site_config": []interface{}{
+				map[string]interface{}{"elastic_instance_minimum": 3.0, "pre_warmed_instance_count": 1.0},
+			}}, planRef),
+			testResource("azurerm_windows_function_app.b", map[string]interface{}{"site_config": []interface{}{
+				map[string]interface{}{"elastic_instance_minimum": 2.0, "pre_warmed_instance_count": 2.0},
+			}}, planRef),
+		}, types.FixedRange(5)},
+		{"maximum burst", "EP3", map[string]interface{}{"worker_count": 1.0, "maximum_elastic_worker_count": 20.0}, nil,
+			types.Range{Min: 1, Expected: 1, Max: 20}},
+	}
+	for _, c := range cases {
+		c.plan["sku_name"], c.plan["location"] = c.sku, "eastus"
+		res := testResource("azurerm_service_plan.ep", c.plan, nil)
+		plan := newAppServicePlan(testGraph(append(c.apps, res)...), res)
+		assert.Equal(t, c.instances, plan.Instances, c.name)
+
+		size := elasticPremiumSizes[c.sku]
+		pricers := plan.pricers()
+		if assert.Len(t, pricers, 2, c.name) {
+			assert.Equal(t, c.instances.Scale(size.Cores), pricers[0].(*meter).Quantity, c.name)
+			assert.Equal(t, c.instances.Scale(size.MemoryGb), pricers[1].(*meter).Quantity, c.name)
+		}
+	}
+}
+
+func TestAppServicePlanFor(t *testing.T) {
+	plan := testResource("azurerm_service_plan.p", nil, nil)
+	app := testResource("azurerm_linux_web_app.app", nil, map[string][]string{"service_plan_id": {"azurerm_service_plan.p"}})
+	slot := testResource("azurerm_linux_web_app_slot.staging", nil, map[string][]string{"app_service_id": {"azurerm_linux_web_app.app"}})
+	orphan := testResource("azurerm_linux_web_app.orphan", nil, map[string][]string{"service_plan_id": {"azurerm_service_plan.missing"}})
+	graph := testGraph(plan, app, slot, orphan)
+
+	assert.Equal(t, plan, appServicePlanFor(graph, app))
+	assert.Equal(t, plan, appServicePlanFor(graph, slot))
+	assert.Nil(t, appServicePlanFor(graph, orphan))
+}
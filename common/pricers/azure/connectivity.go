@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+)
+
+// Scale units included in the hourly price of a Bastion host, beyond which each unit is billed by the hour
+const BASTION_INCLUDED_SCALE_UNITS = 2.0
+
+// bastionHostPricers prices a Bastion host's hours by sku, its scale units beyond those included, and the data it
+// sends out from its usage.  Developer hosts are free.
+func bastionHostPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	sku := getString(values, "sku")
+	if sku == "" {
+		sku = "Basic"
+	}
+	if sku == "Developer" {
+		return []types.Priceable{note("Developer Bastion hosts are free")}
+	}
+	filter := fmt.Sprintf("serviceName eq 'Azure Bastion' and armRegionName eq '%s' and skuName eq '%s'", getString(values, "location"), sku)
+	pricers := []types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Gateway') eq true) and (contains(meterName,'Additional') eq false)", 1)}
+	//Basic hosts always run two scale units
+	if units, _ := getFloat(values, "scale_units"); sku != "Basic" && units > BASTION_INCLUDED_SCALE_UNITS {
+		pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'Additional') eq true)", units-BASTION_INCLUDED_SCALE_UNITS))
+	}
+	return appendMeters(pricers, tieredMeter(usageMeter(usage, "outbound_data_gb", filter+" and (contains(meterName,'Data Transfer Out') eq true)")))
+}
+
+// privateEndpointPricers prices a private endpoint's hours and the data it processes in each direction
+func privateEndpointPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	filter := fmt.Sprintf("serviceName eq 'Virtual Network' and productName eq 'Virtual Network Private Link' and armRegionName eq '%s'", getString(values, "location"))
+	return appendMeters([]types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Private Endpoint') eq true)", 1)},
+		tieredMeter(usageMeter(usage, "ingress_gb", filter+" and (contains(meterName,'Data Processed - Ingress') eq true)")),
+		tieredMeter(usageMeter(usage, "egress_gb", filter+" and (contains(meterName,'Data Processed - Egress') eq true)")),
+	)
+}
+
+// dnsZonePricers prices a hosted public or private DNS zone by the month, and the queries it answers from its usage.
+// DNS zones are global, so they're priced in Zone 1, North America and Europe.
+func dnsZonePricers(private bool, usage types.Usage) []types.Priceable {
+	kind := "Public"
+	if private {
+		kind = "Private"
+	}
+	filter := "serviceName eq 'Azure DNS' and location eq 'Zone 1'"
+	return appendMeters([]types.Priceable{monthlyMeter(filter+fmt.Sprintf(" and meterName eq '%s Zone'", kind), 1)},
+		tieredMeter(usageMeter(usage, "queries", filter+fmt.Sprintf(" and meterName eq '%s Queries'", kind))))
+}
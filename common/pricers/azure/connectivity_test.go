@@ -0,0 +1,84 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestBastionHostScaleUnits(t *testing.T) {
+	cases := []struct {
+		values     map[string]interface{}
+		sku        string
+		additional float64
+	}{
+		{map[string]interface{}{}, "Basic", 0},
+		{map[string]interface{}{"sku": "Basic", "scale_units": 2.0}, "Basic", 0},
+		{map[string]interface{}{"sku": "Standard", "scale_units": 2.0}, "Standard", 0},
+		{map[string]interface{}{"sku": "Standard", "scale_units": 5.0}, "Standard", 3},
+		{map[string]interface{}{"sku": "Premium", "scale_units": 10.0}, "Premium", 8},
+	}
+	for _, c := range cases {
+		c.values["location"] = "eastus"
+		pricers := bastionHostPricers(c.values, types.Usage{})
+		expected := 1
+		if c.additional > 0 {
+			expected = 2
+		}
+		if !assert.Len(t, pricers, expected, "%v", c.values) {
+			continue
+		}
+		assert.Contains(t, pricers[0].(*meter).Filter, "skuName eq '"+c.sku+"'", "%v", c.values)
+		if c.additional > 0 {
+			assert.Equal(t, types.FixedRange(c.additional), pricers[1].(*meter).Quantity, "%v", c.values)
+		}
+	}
+
+	pricers := bastionHostPricers(map[string]interface{}{"sku": "Developer"}, types.Usage{"outbound_data_gb": 100})
+	if assert.Len(t, pricers, 1) {
+		assert.IsType(t, note(""), pricers[0])
+	}
+	pricers = bastionHostPricers(map[string]interface{}{"sku": "Standard"}, types.Usage{"outbound_data_gb": 100})
+	if assert.Len(t, pricers, 2) {
+		assert.True(t, pricers[1].(*meter).Tiered)
+		assert.Equal(t, types.FixedRange(100), pricers[1].(*meter).Quantity)
+	}
+}
+
+func TestPrivateEndpointDataProcessed(t *testing.T) {
+	cases := []struct {
+		usage  types.Usage
+		meters int
+	}{
+		{types.Usage{}, 1},
+		{types.Usage{"ingress_gb": 50}, 2},
+		{types.Usage{"ingress_gb": 50, "egress_gb": 20}, 3},
+	}
+	for _, c := range cases {
+		pricers := privateEndpointPricers(map[string]interface{}{"location": "westeurope"}, c.usage)
+		if assert.Len(t, pricers, c.meters, "%v", c.usage) {
+			assert.Equal(t, perHour, pricers[0].(*meter).Period)
+			assert.Contains(t, pricers[0].(*meter).Filter, "armRegionName eq 'westeurope'")
+		}
+	}
+}
+
+func TestDnsZoneKind(t *testing.T) {
+	cases := []struct {
+		private bool
+		kind    string
+	}{
+		{false, "Public"},
+		{true, "Private"},
+	}
+	for _, c := range cases {
+		pricers := dnsZonePricers(c.private, types.Usage{"queries": 1000000})
+		if !assert.Len(t, pricers, 2, c.kind) {
+			continue
+		}
+		assert.Contains(t, pricers[0].(*meter).Filter, "meterName eq '"+c.kind+" Zone'")
+		assert.Equal(t, perMonth, pricers[0].(*meter).Period)
+		assert.Contains(t, pricers[1].(*meter).Filter, "meterName eq '"+c.kind+" Queries'")
+		assert.True(t, pricers[1].(*meter).Tiered)
+	}
+}
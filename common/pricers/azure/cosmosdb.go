@@ -0,0 +1,126 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"math"
+)
+
+// Autoscale throughput is billed for the highest RU/s used each hour, which never drops below a tenth of the maximum
+const COSMOSDB_AUTOSCALE_MIN_FRACTION = 0.1
+
+// Attributes linking a Cosmos DB database, container, keyspace or table to the account or database it's created in
+var cosmosDbParentAttributes = []string{"account_name", "account_id", "database_name", "cassandra_keyspace_id"}
+
+// How a Cosmos DB database, container, keyspace or table without throughput of its own is billed
+var cosmosDbUnprovisionedNotes = map[string]string{
+	"azurerm_cosmosdb_sql_database":       "no throughput of its own, each of its containers is billed for theirs",
+	"azurerm_cosmosdb_mongo_database":     "no throughput of its own, each of its collections is billed for theirs",
+	"azurerm_cosmosdb_cassandra_keyspace": "no throughput of its own, each of its tables is billed for theirs",
+	"azurerm_cosmosdb_gremlin_database":   "no throughput of its own, each of its graphs is billed for theirs",
+	"azurerm_cosmosdb_sql_container":      "no throughput of its own, it shares the throughput of its database",
+	"azurerm_cosmosdb_mongo_collection":   "no throughput of its own, it shares the throughput of its database",
+	"azurerm_cosmosdb_cassandra_table":    "no throughput of its own, it shares the throughput of its keyspace",
+	"azurerm_cosmosdb_gremlin_graph":      "no throughput of its own, it shares the throughput of its database",
+	"azurerm_cosmosdb_table":              "no throughput of its own, it shares the throughput of its account",
+}
+
+// CosmosDbAccount is billed in every region it's replicated to, for the throughput of its databases and containers
+// and for the data it stores
+type CosmosDbAccount struct {
+	Regions             []string
+	MultipleWriteRegion bool
+	Serverless          bool
+}
+
+func newCosmosDbAccount(values map[string]interface{}) *CosmosDbAccount {
+	a := &CosmosDbAccount{
+		//enable_multiple_write_locations was renamed in v4 of the provider
+		MultipleWriteRegion: getBool(values, "enable_multiple_write_locations") || getBool(values, "multiple_write_locations_enabled"),
+	}
+	for _, location := range getBlocks(values, "geo_location") {
+		a.Regions = append(a.Regions, getString(location, "location"))
+	}
+	if len(a.Regions) == 0 {
+		a.Regions = []string{getString(values, "location")}
+	}
+	for _, capability := range getBlocks(values, "capabilities") {
+		if getString(capability, "name") == "EnableServerless" {
+			a.Serverless = true
+		}
+	}
+	return a
+}
+
+// pricers prices what the account stores in each region and, for serverless accounts, the request units it uses.
+// Provisioned throughput is priced on the databases and containers it's set on.
+func (a *CosmosDbAccount) pricers(usage types.Usage) []types.Priceable {
+	var pricers []types.Priceable
+	for _, region := range a.Regions {
+		filter := fmt.Sprintf("serviceName eq 'Azure Cosmos DB' and armRegionName eq '%s'", region)
+		pricers = appendMeters(pricers, usageMeter(usage, "storage_gb", filter+" and (contains(meterName,'Data Stored') eq true) and (contains(productName,'Analytical') eq false)"))
+		if a.Serverless {
+			pricers = appendMeters(pricers, usageMeter(usage, "request_units", filter+" and (contains(productName,'serverless') eq true) and (contains(meterName,'RU') eq true)"))
+		}
+	}
+	if len(pricers) == 0 {
+		if a.Serverless {
+			return []types.Priceable{note("no usage given for this serverless account, add request_units and storage_gb to a usage profile to price it")}
+		}
+		return []types.Priceable{note("no storage_gb usage given for this account, its storage isn't priced")}
+	}
+	return pricers
+}
+
+/*
+throughputPricers prices the RU/s provisioned on a database or container in each of the account's regions.
+Autoscale throughput is priced from a tenth of its max_throughput up to all of it.  Each hour is billed at the highest
+RU/s it scaled to, so a single burst bills the whole hour at the max, and without an average_throughput in the usage
+that's the throughput it's expected to be billed for.
+*/
+func (a *CosmosDbAccount) throughputPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	var ru types.Range
+	autoscale := false
+	if settings := getBlock(values, "autoscale_settings"); settings != nil {
+		max, _ := getFloat(settings, "max_throughput")
+		ru = types.Range{Min: max * COSMOSDB_AUTOSCALE_MIN_FRACTION, Expected: max, Max: max}
+		if average, ok := usage.Get("average_throughput"); ok {
+			ru.Expected = math.Min(math.Max(average, ru.Min), ru.Max)
+		}
+		autoscale = true
+	} else if throughput, ok := getFloat(values, "throughput"); ok {
+		ru = types.FixedRange(throughput)
+	}
+	var pricers []types.Priceable
+	if ru.Max == 0 {
+		return pricers
+	}
+	for _, region := range a.Regions {
+		pricers = append(pricers, &meter{
+			Filter: fmt.Sprintf("serviceName eq 'Azure Cosmos DB' and armRegionName eq '%s' and (contains(productName,'autoscale') eq %t) and (contains(meterName,'RU/s') eq true) and (contains(meterName,'Multi') eq %t)",
+				region, autoscale, a.MultipleWriteRegion),
+			//Throughput is priced per 100 RU/s
+			Quantity: ru.Scale(0.01),
+			Period:   perHour,
+		})
+	}
+	return pricers
+}
+
+// cosmosDbAccountFor returns the account a database, container, keyspace or table is created in, following a container
+// through the database it's in
+func cosmosDbAccountFor(graph *planGraph, res *planResource) *planResource {
+	for _, attribute := range cosmosDbParentAttributes {
+		for _, parent := range graph.referencedBy(res, attribute) {
+			if parent.Type == "azurerm_cosmosdb_account" {
+				return parent
+			}
+			if parent != res {
+				if account := cosmosDbAccountFor(graph, parent); account != nil {
+					return account
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+	"testing"
+)
+
+func TestCosmosDbAccount(t *testing.T) {
+	values := map[string]interface{}{
+		"location":                         "eastus",
+		"multiple_write_locations_enabled": true,
+		"geo_location": []interface{}{
+			map[string]interface{}{"location": "eastus"},
+			map[string]interface{}{"location": "westus"},
+		},
+		"capabilities": []interface{}{map[string]interface{}{"name": "EnableServerless"}},
+	}
+	account := newCosmosDbAccount(values)
+	assert.Equal(t, []string{"eastus", "westus"}, account.Regions)
+	assert.True(t, account.MultipleWriteRegion)
+	assert.True(t, account.Serverless)
+
+	// storage and request units are billed in each region
+	pricers := account.pricers(types.Usage{"storage_gb": 10, "request_units": 1000000})
+	assert.Len(t, pricers, 4)
+	pricers = account.pricers(types.Usage{})
+	assert.Len(t, pricers, 1)
+	assert.IsType(t, note(""), pricers[0])
+
+	account = newCosmosDbAccount(map[string]interface{}{"location": "northeurope", "enable_multiple_write_locations": false})
+	assert.Equal(t, []string{"northeurope"}, account.Regions)
+	assert.False(t, account.MultipleWriteRegion)
+	assert.False(t, account.Serverless)
+}
+
+func TestCosmosDbThroughput(t *testing.T) {
+	autoscale := func(max float64) map[string]interface{} {
+		return map[string]interface{}{"autoscale_settings": []interface{}{map[string]interface{}{"max_throughput": max}}}
+	}
+	cases := []struct {
+		name       string
+		values     map[string]interface{}
+		usage      types.Usage
+		regions    []string
+		multiWrite bool
+		// in units of 100 RU/s, for each region
+		quantity types.Range
+	}{
+		{"provisioned", map[string]interface{}{"throughput": 400.0}, types.Usage{}, []string{"eastus"}, false, types.FixedRange(4)},
+		{"autoscale", autoscale(4000), types.Usage{}, []string{"eastus"}, false, types.Range{Min: 4, Expected: 40, Max: 40}},
+		{"autoscale with usage", autoscale(4000), types.Usage{"average_throughput": 1500}, []string{"eastus"}, false, types.Range{Min: 4, Expected: 15, Max: 40}},
+		{"autoscale with usage below the minimum", autoscale(4000), types.Usage{"average_throughput": 100}, []string{"eastus"}, false, types.Range{Min: 4, Expected: 4, Max: 40}},
+		{"multi-region multi-write", map[string]interface{}{"throughput": 1000.0}, types.Usage{}, []string{"eastus", "westus", "uksouth"}, true, types.FixedRange(10)},
+	}
+	for _, c := range cases {
+		account := &CosmosDbAccount{Regions: c.regions, MultipleWriteRegion: c.multiWrite}
+		pricers := account.throughputPricers(c.values, c.usage)
+		if !assert.Len(t, pricers, len(c.regions), c.name) {
+			continue
+		}
+		for i, pricer := range pricers {
+			m := pricer.(*meter)
+			assert.InDelta(t, c.quantity.Min, m.Quantity.Min, 0.0001, c.name)
+			assert.InDelta(t, c.quantity.Expected, m.Quantity.Expected, 0.0001, c.name)
+			assert.InDelta(t, c.quantity.Max, m.Quantity.Max, 0.0001, c.name)
+			assert.Contains(t, m.Filter, "armRegionName eq '"+c.regions[i]+"'", c.name)
+			assert.Contains(t, m.Filter, fmt.Sprintf("(contains(meterName,'Multi') eq %t)", c.multiWrite), c.name)
+		}
+	}
+
+	// containers without throughput of their own share their database's
+	assert.Empty(t, (&CosmosDbAccount{Regions: []string{"eastus"}}).throughputPricers(map[string]interface{}{}, types.Usage{}))
+}
+
+func TestCosmosDbUnprovisionedNotes(t *testing.T) {
+	// every resource that can have throughput of its own explains how it's billed without it
+	for resourceType := range usageKeys {
+		if strings.HasPrefix(resourceType, "azurerm_cosmosdb_") && resourceType != "azurerm_cosmosdb_account" {
+			assert.NotEmpty(t, cosmosDbUnprovisionedNotes[resourceType], resourceType)
+		}
+	}
+	assert.Contains(t, cosmosDbUnprovisionedNotes["azurerm_cosmosdb_sql_database"], "containers")
+	assert.Contains(t, cosmosDbUnprovisionedNotes["azurerm_cosmosdb_cassandra_keyspace"], "tables")
+	assert.Contains(t, cosmosDbUnprovisionedNotes["azurerm_cosmosdb_sql_container"], "its database")
+}
@@ -0,0 +1,87 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"sort"
+)
+
+// The public IPs a DDoS protection plan covers for its monthly fee, beyond which each protected IP is billed on top
+const DDOS_INCLUDED_RESOURCES = 100.0
+
+// ddosProtectionPlanPricers prices a DDoS protection plan's monthly fee, plus the overage for each public IP beyond
+// those included that it protects through the virtual networks linked to it
+func ddosProtectionPlanPricers(graph *planGraph, res *planResource) []types.Priceable {
+	filter := fmt.Sprintf("serviceName eq 'Azure DDOS Protection' and armRegionName eq '%s'", getString(res.Values, "location"))
+	pricers := []types.Priceable{monthlyMeter(filter+" and (contains(meterName,'Protection') eq true) and (contains(meterName,'IP') eq false)", 1)}
+	if protected := float64(len(ddosProtectedPublicIps(graph, res))); protected > DDOS_INCLUDED_RESOURCES {
+		pricers = append(pricers, monthlyMeter(filter+" and (contains(meterName,'Overage') eq true)", protected-DDOS_INCLUDED_RESOURCES))
+	}
+	return pricers
+}
+
+// ddosProtectedPublicIps returns the addresses of the public IPs used by resources in the virtual networks a plan
+// protects, either directly or through one of their subnets.  Public IPs on the frontend of a load balancer are only
+// found when something in the network refers to them.
+func ddosProtectedPublicIps(graph *planGraph, plan *planResource) []string {
+	networks := map[string]bool{}
+	for _, vnet := range graph.referencing(plan, "azurerm_virtual_network", "ddos_protection_plan.id") {
+		networks[vnet.ConfigAddress] = true
+		for _, subnet := range graph.referencing(vnet, "azurerm_subnet", "virtual_network_name") {
+			networks[subnet.ConfigAddress] = true
+		}
+	}
+	found := map[string]bool{}
+	for _, res := range graph.Resources {
+		if res.Type == "azurerm_subnet" || !referencesAny(res, networks) {
+			continue
+		}
+		for attribute := range res.References {
+			for _, linked := range graph.referencedBy(res, attribute) {
+				if linked.Type == "azurerm_public_ip" {
+					found[linked.Address] = true
+				}
+			}
+		}
+	}
+	var addresses []string
+	for address := range found {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// referencesAny is true when any attribute of res refers to one of the given configuration addresses
+func referencesAny(res *planResource, addresses map[string]bool) bool {
+	for _, references := range res.References {
+		for _, address := range references {
+			if addresses[address] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flowLogPricers prices the flow logs a Network Watcher collects from its usage, and the traffic analytics run over
+// them when it's enabled, more often at the 10 minute interval.  The first 5GB collected a month are free, which is the
+// bottom tier of the collection meter.
+func flowLogPricers(graph *planGraph, res *planResource, usage types.Usage) []types.Priceable {
+	location := getString(res.Values, "location")
+	if watcher := graph.linkedResource(res, "azurerm_network_watcher", "network_watcher_name"); location == "" && watcher != nil {
+		location = getString(watcher.Values, "location")
+	}
+	filter := fmt.Sprintf("serviceName eq 'Network Watcher' and armRegionName eq '%s'", location)
+	collected := tieredMeter(usageMeter(usage, "logs_collected_gb", filter+" and (contains(meterName,'Flow Logs Collected') eq true)"))
+	if collected == nil {
+		return []types.Priceable{note("no logs_collected_gb usage given for this flow log, add logs_collected_gb to a usage profile to price it")}
+	}
+	pricers := []types.Priceable{collected}
+	if analytics := getBlock(res.Values, "traffic_analytics"); analytics != nil && getBool(analytics, "enabled") {
+		interval, _ := getFloat(analytics, "interval_in_minutes")
+		pricers = appendMeters(pricers, usageMeter(usage, "logs_collected_gb", filter+fmt.Sprintf(
+			" and (contains(meterName,'Traffic Analytics') eq true) and (contains(meterName,'10') eq %t)", interval == 10)))
+	}
+	return append(pricers, note("the storage account and Log Analytics workspace the logs are sent to are billed separately"))
+}
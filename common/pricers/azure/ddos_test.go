@@ -0,0 +1,134 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestDdosProtectedPublicIps(t *testing.T) {
+	plan := testResource("azurerm_network_ddos_protection_plan.ddos", map[string]interface{}{"location": "eastus"}, nil)
+	resources := []*planResource{
+		plan,
+		testResource("azurerm_virtual_network.protected", nil, map[string][]string{"ddos_protection_plan.id": {"azurerm_network_ddos_protection_plan.ddos"}}),
+		testResource("azurerm_subnet.app", nil, map[string][]string{"virtual_network_name": {"azurerm_virtual_network.protected"}}),
+		testResource("azurerm_public_ip.app[0]", nil, nil),
+		testResource("azurerm_public_ip.app[1]", nil, nil),
+		testResource("azurerm_public_ip.bastion", nil, nil),
+		testResource("azurerm_public_ip.gateway", nil, nil),
+		testResource("azurerm_public_ip.unprotected", nil, nil),
+		// counted NICs in a subnet of the protected network, each with its instance of the public IP
+		testResource("azurerm_network_interface.app[0]", nil, map[string][]string{
+			"ip_configuration.subnet_id":            {"azurerm_subnet.app"},
+			"ip_configuration.public_ip_address_id": {"azurerm_public_ip.app"},
+		}),
+		testResource("azurerm_network_interface.app[1]", nil, map[string][]string{
+			"ip_configuration.subnet_id":            {"azurerm_subnet.app"},
+			"ip_configuration.public_ip_address_id": {"azurerm_public_ip.app"},
+		}),
+		// a Bastion host in a subnet it creates inline
+		testResource("azurerm_bastion_host.bastion", nil, map[string][]string{
+			"ip_configuration.subnet_id":            {"azurerm_subnet.app"},
+			"ip_configuration.public_ip_address_id": {"azurerm_public_ip.bastion"},
+		}),
+		// a gateway in a subnet declared inline in the network, so it refers to the network itself
+		testResource("azurerm_application_gateway.gateway", nil, map[string][]string{
+			"gateway_ip_configuration.subnet_id":             {"azurerm_virtual_network.protected"},
+			"frontend_ip_configuration.public_ip_address_id": {"azurerm_public_ip.gateway"},
+		}),
+		// a NIC in a network the plan doesn't protect
+		testResource("azurerm_virtual_network.other", nil, nil),
+		testResource("azurerm_subnet.other", nil, map[string][]string{"virtual_network_name": {"azurerm_virtual_network.other"}}),
+		testResource("azurerm_network_interface.other", nil, map[string][]string{
+			"ip_configuration.subnet_id":            {"azurerm_subnet.other"},
+			"ip_configuration.public_ip_address_id": {"azurerm_public_ip.unprotected"},
+		}),
+	}
+	assert.Equal(t, []string{"azurerm_public_ip.app[0]", "azurerm_public_ip.app[1]", "azurerm_public_ip.bastion", "azurerm_public_ip.gateway"},
+		ddosProtectedPublicIps(testGraph(resources...), plan))
+	assert.Len(t, ddosProtectionPlanPricers(testGraph(resources...), plan), 1)
+}
+
+func TestDdosProtectionOverage(t *testing.T) {
+	cases := []struct {
+		ips      int
+		overage  float64
+		expected int
+	}{
+		{0, 0, 1},
+		{100, 0, 1},
+		{101, 1, 2},
+		{150, 50, 2},
+	}
+	for _, c := range cases {
+		plan := testResource("azurerm_network_ddos_protection_plan.ddos", map[string]interface{}{"location": "eastus"}, nil)
+		resources := []*planResource{
+			plan,
+			testResource("azurerm_virtual_network.vnet", nil, map[string][]string{"ddos_protection_plan.id": {"azurerm_network_ddos_protection_plan.ddos"}}),
+		}
+		for i := 0; i < c.ips; i++ {
+			resources = append(resources,
+				testResource(fmt.Sprintf("azurerm_public_ip.ip[%d]", i), nil, nil),
+				testResource(fmt.Sprintf("azurerm_lb.lb[%d]", i), nil, map[string][]string{
+					"frontend_ip_configuration.subnet_id":            {"azurerm_virtual_network.vnet"},
+					"frontend_ip_configuration.public_ip_address_id": {"azurerm_public_ip.ip"},
+				}))
+		}
+		pricers := ddosProtectionPlanPricers(testGraph(resources...), plan)
+		if !assert.Len(t, pricers, c.expected, "%d IPs", c.ips) {
+			continue
+		}
+		if c.overage > 0 {
+			m := pricers[1].(*meter)
+			assert.Contains(t, m.Filter, "Overage", "%d IPs", c.ips)
+			assert.Equal(t, types.FixedRange(c.overage), m.Quantity, "%d IPs", c.ips)
+		}
+	}
+}
+
+func TestFlowLogTrafficAnalytics(t *testing.T) {
+	cases := []struct {
+		name      string
+		values    map[string]interface{}
+		usage     types.Usage
+		analytics string
+	}{
+		{"no usage", map[string]interface{}{}, types.Usage{}, ""},
+		{"collection only", map[string]interface{}{}, types.Usage{"logs_collected_gb": 20}, ""},
+		{"hourly traffic analytics", map[string]interface{}{"traffic_analytics": []interface{}{
+			map[string]interface{}{"enabled": true, "interval_in_minutes": 60.0},
+		}}, types.Usage{"logs_collected_gb": 20}, "eq false"},
+		{"traffic analytics every 10 minutes", map[string]interface{}{"traffic_analytics": []interface{}{
+			map[string]interface{}{"enabled": true, "interval_in_minutes": 10.0},
+		}}, types.Usage{"logs_collected_gb": 20}, "eq true"},
+		{"traffic analytics disabled", map[string]interface{}{"traffic_analytics": []interface{}{
+			map[string]interface{}{"enabled": false, "interval_in_minutes": 10.0},
+		}}, types.Usage{"logs_collected_gb": 20}, ""},
+	}
+	for _, c := range cases {
+		watcher := testResource("azurerm_network_watcher.watcher", map[string]interface{}{"location": "westeurope"}, nil)
+		res := testResource("azurerm_network_watcher_flow_log.log", c.values, map[string][]string{"network_watcher_name": {"azurerm_network_watcher.watcher"}})
+		pricers := flowLogPricers(testGraph(watcher, res), res, c.usage)
+		if len(c.usage) == 0 {
+			if assert.Len(t, pricers, 1, c.name) {
+				assert.IsType(t, note(""), pricers[0], c.name)
+			}
+			continue
+		}
+		expected := 2
+		if c.analytics != "" {
+			expected = 3
+		}
+		if !assert.Len(t, pricers, expected, c.name) {
+			continue
+		}
+		collected := pricers[0].(*meter)
+		assert.True(t, collected.Tiered, c.name)
+		assert.Contains(t, collected.Filter, "armRegionName eq 'westeurope'", c.name)
+		if c.analytics != "" {
+			assert.Contains(t, pricers[1].(*meter).Filter, "(contains(meterName,'10') "+c.analytics+")", c.name)
+		}
+		assert.IsType(t, note(""), pricers[expected-1], c.name)
+	}
+}
@@ -0,0 +1,176 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Defender for Cloud plan that's billed per resource it protects, found by the resource types it's charged for
+type defenderPlan struct {
+	//Part of the productName in the pricing API, e.g. Microsoft Defender for Servers
+	Product       string
+	ResourceTypes []string
+	Period        meterPeriod
+}
+
+// The Defender plans, keyed by the resource_type of azurerm_security_center_subscription_pricing, that are charged per
+// resource in the plan.  Plans charged per transaction, API call or vCore aren't estimated.
+var defenderPlans = map[string]defenderPlan{
+	"VirtualMachines": {"Servers", []string{"azurerm_virtual_machine", "azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine",
+		"azurerm_virtual_machine_scale_set", "azurerm_linux_virtual_machine_scale_set", "azurerm_windows_virtual_machine_scale_set"}, perHour},
+	"AppServices":                   {"App Service", []string{"azurerm_app_service_plan", "azurerm_service_plan"}, perHour},
+	"SqlServers":                    {"SQL", []string{"azurerm_mssql_server", "azurerm_sql_server", "azurerm_mssql_managed_instance"}, perHour},
+	"OpenSourceRelationalDatabases": {"open-source relational databases", []string{"azurerm_postgresql_flexible_server", "azurerm_mysql_flexible_server", "azurerm_postgresql_server", "azurerm_mysql_server", "azurerm_mariadb_server"}, perHour},
+	"StorageAccounts":               {"Storage", []string{"azurerm_storage_account"}, perMonth},
+	"Containers":                    {"Containers", []string{"azurerm_kubernetes_cluster", "azurerm_kubernetes_cluster_node_pool"}, perHour},
+}
+
+// The family and vCPUs in a VM size such as Standard_D4s_v3, or for a constrained size such as Standard_E8-4s_v3 the
+// vCPUs it runs with
+var vmSizeVcpuRegex = regexp.MustCompile(`^Standard_([A-Z]+)(\d+)(?:-(\d+))?`)
+
+// The memory optimized sizes of the D and DS families, e.g. Standard_D11_v2, are numbered from 11 rather than by their
+// vCPUs
+var dSeriesMemoryOptimizedVcpus = map[string]float64{"11": 2, "12": 4, "13": 8, "14": 16, "15": 20}
+
+// defenderSubscriptionPricers prices each azurerm_security_center_subscription_pricing once the rest of the plan has
+// been.  A subscription has one of each plan, so a second resource configuring the same plan isn't billed again.
+func defenderSubscriptionPricers(graph *planGraph, subscriptions []*planResource, priced []pricedResource) []pricedResource {
+	var plans []pricedResource
+	pricedBy := map[string]string{}
+	for _, res := range subscriptions {
+		resourceType := getString(res.Values, "resource_type")
+		if address, ok := pricedBy[resourceType]; ok {
+			plans = append(plans, pricedResource{resource: res, pricers: []types.Priceable{note(fmt.Sprintf("covered by the cost of %s", address))}})
+			continue
+		}
+		pricedBy[resourceType] = res.Address
+		plans = append(plans, pricedResource{resource: res, pricers: defenderPricers(graph, res, priced)})
+	}
+	return plans
+}
+
+/*
+defenderPricers prices an enabled Defender plan at its rate for each resource of the kind it protects in the same
+estimate, in the region of each resource.  Servers are charged per instance-hour, so scale sets count their instances,
+as a range when they autoscale, and virtual machines only the share of the month they're running.  App Service plans
+count their instances the same way, and databases and storage accounts are charged per server or account.
+Containers are charged per vCPU-hour of the AKS nodes they protect, from the VM size and instances of each node pool.
+*/
+func defenderPricers(graph *planGraph, res *planResource, priced []pricedResource) []types.Priceable {
+	if getString(res.Values, "tier") != "Standard" {
+		return []types.Priceable{note("the Free tier of Defender for Cloud isn't billed")}
+	}
+	resourceType := getString(res.Values, "resource_type")
+	plan, ok := defenderPlans[resourceType]
+	if !ok {
+		return []types.Priceable{note(fmt.Sprintf("the %s plan is billed per transaction, API call or vCore, which isn't estimated", resourceType))}
+	}
+	pricersOf := map[*planResource][]types.Priceable{}
+	for _, p := range priced {
+		pricersOf[p.resource] = p.pricers
+	}
+
+	counts := map[string]types.Range{}
+	var unsized []string
+	for _, other := range graph.Resources {
+		if !containsString(plan.ResourceTypes, other.Type) {
+			continue
+		}
+		count := types.FixedRange(1)
+		switch resourceType {
+		case "VirtualMachines":
+			count = defenderServerInstances(pricersOf[other])
+		case "Containers":
+			//Node pools are priced in the location of their cluster, which they don't carry themselves
+			for _, pricer := range pricersOf[other] {
+				if vm, ok := pricer.(*VirtualMachine); ok {
+					vcpus, ok := vmSizeVcpus(vm.Size)
+					if !ok {
+						unsized = append(unsized, vm.Size)
+						continue
+					}
+					counts[vm.Location] = counts[vm.Location].Add(defenderInstanceHours(vm).Scale(vcpus))
+				}
+			}
+			continue
+		case "AppServices":
+			appPlan := newAppServicePlan(graph, other)
+			if appPlan.isConsumption() {
+				continue
+			}
+			count = appPlan.Instances
+		}
+		location := getString(other.Values, "location")
+		counts[location] = counts[location].Add(count)
+	}
+
+	filter := fmt.Sprintf("serviceName eq 'Microsoft Defender for Cloud' and (contains(productName,'%s') eq true)", plan.Product)
+	switch resourceType {
+	case "VirtualMachines":
+		subplan := getString(res.Values, "subplan")
+		if subplan == "" {
+			subplan = "P2"
+		}
+		filter += fmt.Sprintf(" and (contains(meterName,'%s') eq true)", subplan)
+	case "SqlServers":
+		filter += " and (contains(productName,'Virtual Machines') eq false)"
+	}
+	var locations []string
+	for location := range counts {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	var pricers []types.Priceable
+	for _, location := range locations {
+		pricers = append(pricers, &meter{Filter: filter + fmt.Sprintf(" and armRegionName eq '%s'", location), Quantity: counts[location], Period: plan.Period})
+	}
+	if len(unsized) > 0 {
+		pricers = append(pricers, note(fmt.Sprintf("the vCPUs of the %s node sizes aren't known, so their nodes aren't priced", strings.Join(unsized, ", "))))
+	}
+	if len(pricers) == 0 {
+		return []types.Priceable{note(fmt.Sprintf("there are no resources for the %s plan to protect in this estimate", resourceType))}
+	}
+	return pricers
+}
+
+// defenderServerInstances is the instance-hours of a virtual machine or scale set as a share of the month, from the
+// instances and uptime it was priced with
+func defenderServerInstances(pricers []types.Priceable) types.Range {
+	for _, pricer := range pricers {
+		if vm, ok := pricer.(*VirtualMachine); ok {
+			return defenderInstanceHours(vm)
+		}
+	}
+	return types.FixedRange(1)
+}
+
+// defenderInstanceHours is the instances of a VM pricer weighted by the share of the month they're running
+func defenderInstanceHours(vm *VirtualMachine) types.Range {
+	count := vm.countRange()
+	if vm.Uptime != nil {
+		count = count.Multiply(*vm.Uptime)
+	}
+	return count
+}
+
+// vmSizeVcpus reads the number of vCPUs from a VM size name, e.g. 4 for Standard_D4s_v3, Standard_E8-4s_v3 and
+// Standard_D12_v2
+func vmSizeVcpus(size string) (float64, bool) {
+	match := vmSizeVcpuRegex.FindStringSubmatch(size)
+	if match == nil {
+		return 0, false
+	}
+	vcpus := match[2]
+	if match[3] != "" {
+		vcpus = match[3]
+	} else if count, ok := dSeriesMemoryOptimizedVcpus[vcpus]; ok && (match[1] == "D" || match[1] == "DS") {
+		return count, true
+	}
+	count, err := strconv.ParseFloat(vcpus, 64)
+	return count, err == nil && count > 0
+}
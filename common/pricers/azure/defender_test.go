@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+	"testing"
+)
+
+func TestDefenderPlanResources(t *testing.T) {
+	vm := testResource("azurerm_linux_virtual_machine.dev", map[string]interface{}{"location": "eastus"}, nil)
+	vmss := testResource("azurerm_linux_virtual_machine_scale_set.web", map[string]interface{}{"location": "eastus"}, nil)
+	other := testResource("azurerm_windows_virtual_machine.eu", map[string]interface{}{"location": "westeurope"}, nil)
+	webPlan := testResource("azurerm_service_plan.web", map[string]interface{}{"location": "eastus", "sku_name": "S1", "worker_count": 2.0}, nil)
+	euPlan := testResource("azurerm_service_plan.eu", map[string]interface{}{"location": "westeurope", "sku_name": "P1v3", "worker_count": 3.0}, nil)
+	functions := testResource("azurerm_service_plan.functions", map[string]interface{}{"location": "westeurope", "sku_name": "Y1"}, nil)
+	cluster := testResource("azurerm_kubernetes_cluster.aks", map[string]interface{}{"location": "eastus"}, nil)
+	pool := testResource("azurerm_kubernetes_cluster_node_pool.memory", nil, map[string][]string{"kubernetes_cluster_id": {"azurerm_kubernetes_cluster.aks"}})
+	legacyPool := testResource("azurerm_kubernetes_cluster_node_pool.legacy", nil, map[string][]string{"kubernetes_cluster_id": {"azurerm_kubernetes_cluster.aks"}})
+
+	quarter := types.FixedRange(0.25)
+	priced := []pricedResource{
+		{vm, []types.Priceable{&VirtualMachine{Size: "Standard_B2ms", Location: "eastus", Count: 1, Uptime: &quarter}}},
+		{vmss, []types.Priceable{&VirtualMachine{Size: "Standard_D2s_v3", Location: "eastus", Count: 3, MinCount: 2, MaxCount: 6}}},
+		{other, []types.Priceable{&VirtualMachine{Size: "Standard_D2s_v3", Location: "westeurope", Count: 1}}},
+		{cluster, []types.Priceable{&AksCluster{}, &VirtualMachine{Size: "Standard_D4s_v3", Location: "eastus", Count: 3}}},
+		{pool, []types.Priceable{&VirtualMachine{Size: "Standard_E8-4s_v3", Location: "eastus", Count: 2, MinCount: 1, MaxCount: 5}}},
+		{legacyPool, []types.Priceable{&VirtualMachine{Size: "Basic_A1", Location: "eastus", Count: 1}}},
+	}
+	graph := testGraph(vm, vmss, other, webPlan, euPlan, functions, cluster, pool, legacyPool)
+
+	cases := []struct {
+		resourceType string
+		counts       map[string]types.Range
+		notes        int
+	}{
+		{"VirtualMachines", map[string]types.Range{
+			"eastus":     {Min: 2.25, Expected: 3.25, Max: 6.25},
+			"westeurope": types.FixedRange(1),
+		}, 0},
+		// the consumption plan isn't billed for Defender
+		{"AppServices", map[string]types.Range{
+			"eastus":     types.FixedRange(2),
+			"westeurope": types.FixedRange(3),
+		}, 0},
+		// 3 nodes of 4 vCPUs and 1 to 5 nodes constrained to 4 vCPUs, and a size whose vCPUs aren't known
+		{"Containers", map[string]types.Range{
+			"eastus": {Min: 16, Expected: 20, Max: 32},
+		}, 1},
+		{"StorageAccounts", nil, 1},
+		{"Api", nil, 1},
+	}
+	for _, c := range cases {
+		res := testResource("azurerm_security_center_subscription_pricing.plan", map[string]interface{}{"tier": "Standard", "resource_type": c.resourceType}, nil)
+		counts := map[string]types.Range{}
+		notes := 0
+		for _, pricer := range defenderPricers(graph, res, priced) {
+			switch p := pricer.(type) {
+			case *meter:
+				for _, location := range []string{"eastus", "westeurope"} {
+					if strings.Contains(p.Filter, "armRegionName eq '"+location+"'") {
+						counts[location] = p.Quantity
+					}
+				}
+			case note:
+				notes++
+			}
+		}
+		if c.counts == nil {
+			c.counts = map[string]types.Range{}
+		}
+		assert.Equal(t, c.counts, counts, c.resourceType)
+		assert.Equal(t, c.notes, notes, c.resourceType)
+	}
+
+	free := testResource("azurerm_security_center_subscription_pricing.free", map[string]interface{}{"tier": "Free", "resource_type": "VirtualMachines"}, nil)
+	if pricers := defenderPricers(graph, free, priced); assert.Len(t, pricers, 1) {
+		assert.IsType(t, note(""), pricers[0])
+	}
+}
+
+func TestDefenderSubscriptionsDeduplicated(t *testing.T) {
+	vm := testResource("azurerm_linux_virtual_machine.vm", map[string]interface{}{"location": "eastus"}, nil)
+	subscriptions := []*planResource{
+		testResource("azurerm_security_center_subscription_pricing.servers", map[string]interface{}{"tier": "Standard", "resource_type": "VirtualMachines"}, nil),
+		testResource("azurerm_security_center_subscription_pricing.storage", map[string]interface{}{"tier": "Standard", "resource_type": "StorageAccounts"}, nil),
+		testResource("module.security.azurerm_security_center_subscription_pricing.servers", map[string]interface{}{"tier": "Standard", "resource_type": "VirtualMachines"}, nil),
+	}
+	priced := []pricedResource{{vm, []types.Priceable{&VirtualMachine{Size: "Standard_D2s_v3", Location: "eastus", Count: 1}}}}
+	plans := defenderSubscriptionPricers(testGraph(append(subscriptions, vm)...), subscriptions, priced)
+	if !assert.Len(t, plans, 3) {
+		return
+	}
+	assert.IsType(t, &meter{}, plans[0].pricers[0])
+	assert.Equal(t, []types.Priceable{note("covered by the cost of azurerm_security_center_subscription_pricing.servers")}, plans[2].pricers)
+}
+
+func TestVmSizeVcpus(t *testing.T) {
+	cases := []struct {
+		size  string
+		vcpus float64
+		ok    bool
+	}{
+		{"Standard_D4s_v3", 4, true},
+		{"Standard_DS2_v2", 2, true},
+		{"Standard_B2ms", 2, true},
+		{"Standard_NC24ads_A100_v4", 24, true},
+		{"Standard_E8-4s_v3", 4, true},
+		{"Standard_M128ms", 128, true},
+		{"Standard_D11_v2", 2, true},
+		{"Standard_D12_v2", 4, true},
+		{"Standard_D13_v2", 8, true},
+		{"Standard_D14_v2", 16, true},
+		{"Standard_D15_v2", 20, true},
+		{"Standard_DS11_v2", 2, true},
+		{"Standard_DS12_v2", 4, true},
+		{"Standard_DS13_v2", 8, true},
+		{"Standard_DS14_v2", 16, true},
+		{"Standard_DS15_v2", 20, true},
+		{"Standard_DS13-4_v2", 4, true},
+		{"Standard_E16_v3", 16, true},
+		{"Basic_A1", 0, false},
+		{"", 0, false},
+	}
+	for _, c := range cases {
+		vcpus, ok := vmSizeVcpus(c.size)
+		assert.Equal(t, c.ok, ok, c.size)
+		assert.Equal(t, c.vcpus, vcpus, c.size)
+	}
+}
@@ -8,13 +8,20 @@ import (
 	"strings"
 )
 
-const MONTH_HOURS = 730.0
+const (
+	//Default OS disk sizes for marketplace images, used when disk_size_gb isn't known until apply
+	DEFAULT_LINUX_OS_DISK_GB   = 30.0
+	DEFAULT_WINDOWS_OS_DISK_GB = 127.0
+)
 
 type AzureDisk struct {
 	SizeInGb float64
 	Location string
 	SkuTier  string
 	Count    int
+	//The range of disks there could be when they belong to an autoscaling group.  Both are zero when the count is fixed.
+	MinCount int
+	MaxCount int
 }
 
 var storageToProductMap = map[string]string{
@@ -37,12 +44,12 @@ func (v *AzureDisk) GenerateQuery(context.Context) string {
 	return baseQuery
 }
 
-func (v *AzureDisk) GetHourlyPrice(ctx context.Context) float64 {
+func (v *AzureDisk) GetHourlyPrice(ctx context.Context) types.Range {
 	unitPrice := 0.0
 	disks, err := types.ExecuteAzurePriceQuery(ctx, v)
 	if err != nil {
 		klog.Error(err)
-		return unitPrice
+		return types.FixedRange(unitPrice)
 	}
 	//Assume that the first one is the one we want
 	if len(disks.Items) > 0 {
@@ -50,11 +57,18 @@ func (v *AzureDisk) GetHourlyPrice(ctx context.Context) float64 {
 	}
 	if v.SkuTier == "UltraSSD_LRS" {
 		//This one is metered in per GB
-		return unitPrice * v.SizeInGb * float64(v.Count)
+		return v.countRange().Scale(unitPrice * v.SizeInGb)
 	} else {
 		//This one is metered by tier
-		return (unitPrice * float64(v.Count)) / MONTH_HOURS
+		return v.countRange().Scale(unitPrice / types.MonthHours(ctx))
+	}
+}
+
+func (v *AzureDisk) countRange() types.Range {
+	if v.MaxCount == 0 {
+		return types.FixedRange(float64(v.Count))
 	}
+	return types.Range{Min: float64(v.MinCount), Expected: float64(v.Count), Max: float64(v.MaxCount)}
 }
 
 func (v *AzureDisk) getDiskSize() string {
@@ -0,0 +1,106 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+)
+
+// Edge services are global, and the data they send and the requests they serve are priced in Zone 1, North America and
+// Europe
+const EDGE_PRICING_ZONE = "location eq 'Zone 1'"
+
+// Traffic Manager endpoint types and whether their health checks are priced as Azure or external endpoints
+var trafficManagerEndpointTypes = map[string]string{
+	"azurerm_traffic_manager_azure_endpoint":    "Azure",
+	"azurerm_traffic_manager_nested_endpoint":   "Azure",
+	"azurerm_traffic_manager_external_endpoint": "External",
+}
+
+// frontDoorProfilePricers prices a Standard or Premium Front Door profile's base fee by the month, and the requests and
+// data it serves from its usage
+func frontDoorProfilePricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	tier := strings.SplitN(getString(values, "sku_name"), "_", 2)[0]
+	filter := fmt.Sprintf("serviceName eq 'Azure Front Door Service' and skuName eq '%s'", tier)
+	return appendMeters([]types.Priceable{monthlyMeter(filter+" and (contains(meterName,'Base Fee') eq true)", 1)},
+		usageMeter(usage, "requests", filter+" and "+EDGE_PRICING_ZONE+" and (contains(meterName,'Requests') eq true)"),
+		tieredMeter(usageMeter(usage, "outbound_data_gb", filter+" and "+EDGE_PRICING_ZONE+" and (contains(meterName,'Data Transfer Out') eq true)")),
+	)
+}
+
+// classicFrontDoorPricers prices a classic Front Door by its routing rules, and the data it serves from its usage
+func classicFrontDoorPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	filter := "serviceName eq 'Azure Front Door Service' and (contains(productName,'Classic') eq true)"
+	rules := float64(len(getBlocks(values, "routing_rule")))
+	return appendMeters([]types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Routing Rules') eq true)", rules)},
+		tieredMeter(usageMeter(usage, "outbound_data_gb", filter+" and "+EDGE_PRICING_ZONE+" and (contains(meterName,'Data Transfer Out') eq true)")),
+	)
+}
+
+// frontDoorWafPolicyPricers prices a classic or Standard Front Door WAF policy by the month, with each custom rule and
+// managed rule set on top, and the requests it inspects from its usage
+func frontDoorWafPolicyPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	filter := "serviceName eq 'Web Application Firewall' and (contains(productName,'Front Door') eq true)"
+	pricers := []types.Priceable{monthlyMeter(filter+" and (contains(meterName,'Policy') eq true)", 1)}
+	if rules := float64(len(getBlocks(values, "custom_rule"))); rules > 0 {
+		pricers = append(pricers, monthlyMeter(filter+" and (contains(meterName,'Rule') eq true) and (contains(meterName,'Managed') eq false)", rules))
+	}
+	if sets := float64(len(getBlocks(values, "managed_rule"))); sets > 0 {
+		pricers = append(pricers, monthlyMeter(filter+" and (contains(meterName,'Managed Rule') eq true)", sets))
+	}
+	return appendMeters(pricers, usageMeter(usage, "requests", filter+" and (contains(meterName,'Requests') eq true)"))
+}
+
+// cdnEndpointPricers prices the data an endpoint serves from its usage, at the rate of its profile's sku, e.g.
+// Standard_Microsoft
+func cdnEndpointPricers(profile *planResource, usage types.Usage) []types.Priceable {
+	parts := strings.SplitN(getString(profile.Values, "sku"), "_", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	transfer := tieredMeter(usageMeter(usage, "outbound_data_gb", fmt.Sprintf(
+		"serviceName eq 'Content Delivery Network' and "+EDGE_PRICING_ZONE+" and skuName eq '%s' and (contains(productName,'%s') eq true) and (contains(meterName,'Data Transfer') eq true)",
+		parts[0], parts[1])))
+	if transfer == nil {
+		return []types.Priceable{note("no outbound_data_gb usage given for this endpoint, the data it serves isn't priced")}
+	}
+	return []types.Priceable{transfer}
+}
+
+// trafficManagerProfilePricers prices the health checks of a profile's endpoints by the month, with the fast interval
+// add-on when it probes every 10 seconds, and the DNS queries it answers from its usage
+func trafficManagerProfilePricers(graph *planGraph, res *planResource, usage types.Usage) []types.Priceable {
+	filter := "serviceName eq 'Traffic Manager'"
+	endpoints := map[string]float64{}
+	for endpointType, kind := range trafficManagerEndpointTypes {
+		endpoints[kind] += float64(len(graph.referencing(res, endpointType, "profile_id")))
+	}
+	//Before v3 of the provider every endpoint was an azurerm_traffic_manager_endpoint with a type
+	for _, endpoint := range graph.referencing(res, "azurerm_traffic_manager_endpoint", "profile_name") {
+		if getString(endpoint.Values, "type") == "externalEndpoints" {
+			endpoints["External"]++
+		} else {
+			endpoints["Azure"]++
+		}
+	}
+	var pricers []types.Priceable
+	fast := false
+	if monitor := getBlock(res.Values, "monitor_config"); monitor != nil {
+		interval, _ := getFloat(monitor, "interval_in_seconds")
+		fast = interval == 10
+	}
+	for _, kind := range []string{"Azure", "External"} {
+		if endpoints[kind] == 0 {
+			continue
+		}
+		pricers = append(pricers, monthlyMeter(filter+fmt.Sprintf(" and (contains(meterName,'%s Endpoint') eq true) and (contains(meterName,'Fast') eq false)", kind), endpoints[kind]))
+		if fast {
+			pricers = append(pricers, monthlyMeter(filter+fmt.Sprintf(" and (contains(meterName,'Fast Interval') eq true) and (contains(meterName,'%s') eq true)", kind), endpoints[kind]))
+		}
+	}
+	pricers = appendMeters(pricers, tieredMeter(usageMeter(usage, "dns_queries", filter+" and (contains(meterName,'DNS Queries') eq true)")))
+	if len(pricers) == 0 {
+		return []types.Priceable{note("no endpoints or dns_queries usage for this profile, it isn't billed until it has some")}
+	}
+	return pricers
+}
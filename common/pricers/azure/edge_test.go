@@ -0,0 +1,91 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestTrafficManagerEndpoints(t *testing.T) {
+	profile := map[string][]string{"profile_id": {"azurerm_traffic_manager_profile.tm"}}
+	legacyProfile := map[string][]string{"profile_name": {"azurerm_traffic_manager_profile.tm"}}
+	type health struct {
+		meterName string
+		endpoints float64
+	}
+	cases := []struct {
+		name      string
+		endpoints []*planResource
+		interval  float64
+		health    []health
+	}{
+		{"azure and external endpoints", []*planResource{
+			testResource("azurerm_traffic_manager_azure_endpoint.a", nil, profile),
+			testResource("azurerm_traffic_manager_nested_endpoint.b", nil, profile),
+			testResource("azurerm_traffic_manager_external_endpoint.c", nil, profile),
+		}, 30, []health{{"Azure Endpoint", 2}, {"External Endpoint", 1}}},
+		{"counted endpoints with the fast interval", []*planResource{
+			testResource("azurerm_traffic_manager_external_endpoint.c[0]", nil, profile),
+			testResource("azurerm_traffic_manager_external_endpoint.c[1]", nil, profile),
+			testResource("azurerm_traffic_manager_external_endpoint.c[2]", nil, profile),
+		}, 10, []health{{"External Endpoint", 3}, {"Fast Interval", 3}}},
+		{"endpoints from before v3 of the provider", []*planResource{
+			testResource("azurerm_traffic_manager_endpoint.a", map[string]interface{}{"type": "azureEndpoints"}, legacyProfile),
+			testResource("azurerm_traffic_manager_endpoint.b", map[string]interface{}{"type": "externalEndpoints"}, legacyProfile),
+		}, 30, []health{{"Azure Endpoint", 1}, {"External Endpoint", 1}}},
+		{"endpoints of another profile", []*planResource{
+			testResource("azurerm_traffic_manager_azure_endpoint.a", nil, map[string][]string{"profile_id": {"azurerm_traffic_manager_profile.other"}}),
+		}, 30, nil},
+	}
+	for _, c := range cases {
+		res := testResource("azurerm_traffic_manager_profile.tm", map[string]interface{}{
+			"monitor_config": []interface{}{map[string]interface{}{"interval_in_seconds": c.interval}},
+		}, nil)
+		pricers := trafficManagerProfilePricers(testGraph(append(c.endpoints, res)...), res, types.Usage{"dns_queries": 1000000})
+		if !assert.Len(t, pricers, len(c.health)+1, c.name) {
+			continue
+		}
+		for i, h := range c.health {
+			m := pricers[i].(*meter)
+			assert.Contains(t, m.Filter, "(contains(meterName,'"+h.meterName+"') eq true)", c.name)
+			assert.Equal(t, types.FixedRange(h.endpoints), m.Quantity, c.name)
+		}
+		assert.True(t, pricers[len(c.health)].(*meter).Tiered, c.name)
+	}
+
+	res := testResource("azurerm_traffic_manager_profile.tm", nil, nil)
+	pricers := trafficManagerProfilePricers(testGraph(res), res, types.Usage{})
+	if assert.Len(t, pricers, 1) {
+		assert.IsType(t, note(""), pricers[0])
+	}
+}
+
+func TestCdnEndpointSku(t *testing.T) {
+	cases := []struct {
+		sku     string
+		tier    string
+		product string
+	}{
+		{"Standard_Microsoft", "Standard", "Microsoft"},
+		{"Standard_Verizon", "Standard", "Verizon"},
+		{"Premium_Verizon", "Premium", "Verizon"},
+		{"Standard_ChinaCdn", "Standard", "ChinaCdn"},
+	}
+	for _, c := range cases {
+		profile := testResource("azurerm_cdn_profile.cdn", map[string]interface{}{"sku": c.sku}, nil)
+		pricers := cdnEndpointPricers(profile, types.Usage{"outbound_data_gb": 500})
+		if !assert.Len(t, pricers, 1, c.sku) {
+			continue
+		}
+		m := pricers[0].(*meter)
+		assert.Contains(t, m.Filter, "skuName eq '"+c.tier+"'", c.sku)
+		assert.Contains(t, m.Filter, "(contains(productName,'"+c.product+"') eq true)", c.sku)
+		assert.True(t, m.Tiered, c.sku)
+	}
+
+	profile := testResource("azurerm_cdn_profile.cdn", map[string]interface{}{"sku": "Standard_Microsoft"}, nil)
+	if pricers := cdnEndpointPricers(profile, types.Usage{}); assert.Len(t, pricers, 1) {
+		assert.IsType(t, note(""), pricers[0])
+	}
+	assert.Empty(t, cdnEndpointPricers(testResource("azurerm_cdn_profile.cdn", map[string]interface{}{"sku": "Standard"}, nil), types.Usage{}))
+}
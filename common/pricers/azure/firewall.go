@@ -0,0 +1,51 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+)
+
+// firewallPricers prices an Azure Firewall's deployment hours by its sku_tier and the data it processes.  A firewall
+// with a virtual_hub block is deployed in a secured virtual hub, which is billed on its own meters along with the
+// public IPs the hub gives it.
+func firewallPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	location := getString(values, "location")
+	tier := getString(values, "sku_tier")
+	if tier == "" {
+		tier = "Standard"
+	}
+	hub := getBlock(values, "virtual_hub")
+	filter := fmt.Sprintf("serviceName eq 'Azure Firewall' and armRegionName eq '%s' and skuName eq '%s' and (contains(productName,'Secured Virtual Hub') eq %t)",
+		location, tier, hub != nil)
+	pricers := appendMeters([]types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Deployment') eq true)", 1)},
+		usageMeter(usage, "data_processed_gb", filter+" and (contains(meterName,'Data Processed') eq true)"))
+	if hub != nil {
+		ips, ok := getFloat(hub, "public_ip_count")
+		if !ok || ips < 1 {
+			ips = 1
+		}
+		pricers = append(pricers, publicIpMeter(location, "Standard", "Static", false, ips))
+	}
+	return pricers
+}
+
+// firewallPolicyPricers prices a policy that is billed separately, which is one associated with more than one
+// firewall, once for each region those firewalls are in.  A policy used by a single firewall is free.
+func firewallPolicyPricers(graph *planGraph, res *planResource) []types.Priceable {
+	firewalls := graph.referencing(res, "azurerm_firewall", "firewall_policy_id")
+	if len(firewalls) < 2 {
+		return []types.Priceable{note("policies associated with a single firewall are free")}
+	}
+	var regions []string
+	for _, firewall := range firewalls {
+		if region := getString(firewall.Values, "location"); !containsString(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+	var pricers []types.Priceable
+	for _, region := range regions {
+		pricers = append(pricers, monthlyMeter(fmt.Sprintf(
+			"serviceName eq 'Azure Firewall Manager' and armRegionName eq '%s' and (contains(meterName,'Policy') eq true)", region), 1))
+	}
+	return pricers
+}
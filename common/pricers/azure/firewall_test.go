@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFirewallPolicyRegions(t *testing.T) {
+	policy := testResource("azurerm_firewall_policy.example", nil, nil)
+	firewall := func(address, location string) *planResource {
+		return testResource(address, map[string]interface{}{"location": location},
+			map[string][]string{"firewall_policy_id": {"azurerm_firewall_policy.example"}})
+	}
+	cases := []struct {
+		name      string
+		firewalls []*planResource
+		// the regions the policy is billed in, or none when it's free
+		regions []string
+	}{
+		{"unused", nil, nil},
+		{"single firewall", []*planResource{firewall("azurerm_firewall.a", "eastus")}, nil},
+		{"two firewalls in one region", []*planResource{firewall("azurerm_firewall.a", "eastus"), firewall("azurerm_firewall.b", "eastus")}, []string{"eastus"}},
+		{"firewalls in two regions", []*planResource{firewall("azurerm_firewall.a", "eastus"), firewall("azurerm_firewall.b", "westeurope"), firewall("azurerm_firewall.c", "eastus")}, []string{"eastus", "westeurope"}},
+		{"counted firewalls", []*planResource{firewall("azurerm_firewall.hub[0]", "eastus"), firewall("azurerm_firewall.hub[1]", "uksouth")}, []string{"eastus", "uksouth"}},
+	}
+	for _, c := range cases {
+		pricers := firewallPolicyPricers(testGraph(append([]*planResource{policy}, c.firewalls...)...), policy)
+		if c.regions == nil {
+			if assert.Len(t, pricers, 1, c.name) {
+				assert.IsType(t, note(""), pricers[0], c.name)
+			}
+			continue
+		}
+		if !assert.Len(t, pricers, len(c.regions), c.name) {
+			continue
+		}
+		for i, region := range c.regions {
+			assert.Contains(t, pricers[i].(*meter).Filter, "armRegionName eq '"+region+"'", c.name)
+		}
+	}
+}
+
+func TestFirewallSecuredHub(t *testing.T) {
+	pricers := firewallPricers(map[string]interface{}{"location": "eastus", "sku_tier": "Premium"}, nil)
+	if assert.Len(t, pricers, 1) {
+		assert.Contains(t, pricers[0].(*meter).Filter, "skuName eq 'Premium' and (contains(productName,'Secured Virtual Hub') eq false)")
+	}
+
+	pricers = firewallPricers(map[string]interface{}{
+		"location":    "eastus",
+		"virtual_hub": []interface{}{map[string]interface{}{"public_ip_count": 3.0}},
+	}, nil)
+	if assert.Len(t, pricers, 2) {
+		assert.Contains(t, pricers[0].(*meter).Filter, "skuName eq 'Standard' and (contains(productName,'Secured Virtual Hub') eq true)")
+		assert.Equal(t, 3.0, pricers[1].(*meter).Quantity.Expected)
+	}
+}
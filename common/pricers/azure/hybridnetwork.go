@@ -0,0 +1,176 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"math"
+	"strings"
+)
+
+// Site to site tunnels included in the price of a VPN gateway, beyond which each connection is billed by the hour
+const VPN_GATEWAY_INCLUDED_S2S_TUNNELS = 10.0
+
+// Routing infrastructure units included with a virtual hub, beyond which each is billed by the hour
+const VIRTUAL_HUB_INCLUDED_ROUTING_UNITS = 2.0
+
+// ExpressRoute circuit families and how the pricing API's sku names spell them
+var expressRouteFamilies = map[string]string{
+	"MeteredData":   "Metered Data",
+	"UnlimitedData": "Unlimited Data",
+}
+
+// ExpressRoute circuits are priced by the billing zone of their geography, found here from the start of their location
+var expressRouteZones = []struct {
+	Zone    string
+	Regions []string
+}{
+	{"Zone 1", []string{"eastus", "westus", "centralus", "northcentralus", "southcentralus", "westcentralus", "canada", "northeurope",
+		"westeurope", "uk", "france", "germany", "switzerland", "norway", "sweden", "poland", "italy", "spain"}},
+	{"Zone 2", []string{"eastasia", "southeastasia", "japan", "australia", "korea", "centralindia", "southindia", "westindia",
+		"jioindia", "indonesia", "malaysia", "newzealand", "taiwan"}},
+	{"Zone 3", []string{"brazil", "southafrica", "uae", "qatar"}},
+}
+
+// virtualNetworkGatewayPricers prices a VPN or ExpressRoute virtual network gateway's hours, and for a VPN gateway its
+// site to site connections beyond those included in its sku
+func virtualNetworkGatewayPricers(graph *planGraph, res *planResource) []types.Priceable {
+	location := getString(res.Values, "location")
+	sku := getString(res.Values, "sku")
+	if getString(res.Values, "type") == "ExpressRoute" {
+		return []types.Priceable{hourlyMeter(fmt.Sprintf(
+			"serviceName eq 'ExpressRoute' and armRegionName eq '%s' and (contains(productName,'Gateway') eq true) and skuName eq '%s'", location, sku), 1)}
+	}
+
+	filter := fmt.Sprintf("serviceName eq 'VPN Gateway' and armRegionName eq '%s' and skuName eq '%s'", location, sku)
+	pricers := []types.Priceable{hourlyMeter(filter+" and (contains(meterName,'S2S') eq false) and (contains(meterName,'P2S') eq false)", 1)}
+	var tunnels float64
+	for _, connection := range graph.referencing(res, "azurerm_virtual_network_gateway_connection", "virtual_network_gateway_id") {
+		if getString(connection.Values, "type") == "IPsec" {
+			tunnels++
+		}
+	}
+	if tunnels > VPN_GATEWAY_INCLUDED_S2S_TUNNELS {
+		pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'S2S') eq true)", tunnels-VPN_GATEWAY_INCLUDED_S2S_TUNNELS))
+	}
+	if getBool(res.Values, "active_active") {
+		//Both instances of an active-active gateway are covered by its hourly price, but each needs a public IP
+		pricers = append(pricers, note("active-active gateways are billed the same gateway hours, the public IP of the second instance is priced as an azurerm_public_ip"))
+	}
+	return pricers
+}
+
+// expressRouteCircuitPricers prices a circuit's monthly port fee by tier, family and bandwidth, and the outbound data
+// of a metered circuit from its usage, in the billing zone of the circuit's location.  A circuit whose location isn't in
+// a known zone is priced from the cheapest zone to the dearest, and expected to cost what it would in Zone 1.
+func expressRouteCircuitPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	sku := getBlock(values, "sku")
+	tier, family := getString(sku, "tier"), expressRouteFamilies[getString(sku, "family")]
+	bandwidth, _ := getFloat(values, "bandwidth_in_mbps")
+	zone, ok := expressRouteZone(getString(values, "location"))
+	zones := []string{zone}
+	if !ok {
+		zones = nil
+		for _, z := range expressRouteZones {
+			zones = append(zones, z.Zone)
+		}
+	}
+	filter := "serviceName eq 'ExpressRoute' and (contains(productName,'Circuit') eq true)"
+	port, transfer := zoneSpread{}, zoneSpread{}
+	for _, zone := range zones {
+		zoneFilter := filter + fmt.Sprintf(" and location eq '%s' and skuName eq '%s %s'", zone, tier, family)
+		port = append(port, monthlyMeter(zoneFilter+fmt.Sprintf(" and (contains(meterName,'%s') eq true)", expressRouteSpeed(bandwidth)), 1))
+		if strings.HasPrefix(family, "Metered") {
+			if m := usageMeter(usage, "outbound_data_gb", zoneFilter+" and (contains(meterName,'Data Transfer Out') eq true)"); m != nil {
+				transfer = append(transfer, m)
+			}
+		}
+	}
+	var pricers []types.Priceable
+	for _, spread := range []zoneSpread{port, transfer} {
+		if len(spread) == 1 {
+			pricers = append(pricers, spread[0])
+		} else if len(spread) > 1 {
+			pricers = append(pricers, spread)
+		}
+	}
+	if !ok {
+		pricers = append(pricers, note(fmt.Sprintf("the ExpressRoute billing zone of %q isn't known, so the circuit is priced across every zone", getString(values, "location"))))
+	}
+	return pricers
+}
+
+// expressRouteZone finds the billing zone of a location such as "West Europe" or westeurope
+func expressRouteZone(location string) (string, bool) {
+	region := strings.ToLower(strings.Replace(location, " ", "", -1))
+	for _, zone := range expressRouteZones {
+		for _, prefix := range zone.Regions {
+			if strings.HasPrefix(region, prefix) {
+				return zone.Zone, true
+			}
+		}
+	}
+	return "", false
+}
+
+// expressRouteSpeed is a circuit bandwidth the way meter names spell it, e.g. 200 Mbps or 10 Gbps
+func expressRouteSpeed(bandwidth float64) string {
+	if bandwidth >= 1000 {
+		return fmt.Sprintf("%g Gbps", bandwidth/1000)
+	}
+	return fmt.Sprintf("%g Mbps", bandwidth)
+}
+
+// zoneSpread prices the same meter in several billing zones when it isn't known which one applies, from the cheapest to
+// the dearest and expected to cost what it does in the first
+type zoneSpread []*meter
+
+func (z zoneSpread) GetHourlyPrice(ctx context.Context) types.Range {
+	var spread types.Range
+	for i, m := range z {
+		price := m.GetHourlyPrice(ctx)
+		if i == 0 {
+			spread = price
+			continue
+		}
+		spread.Min = math.Min(spread.Min, price.Min)
+		spread.Max = math.Max(spread.Max, price.Max)
+	}
+	return spread
+}
+
+// virtualHubPricers prices a Standard virtual hub's hours, its routing infrastructure units beyond those included and
+// the data it processes.  Basic hubs are free.
+func virtualHubPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	if getString(values, "sku") == "Basic" {
+		return []types.Priceable{note("Basic virtual hubs are free")}
+	}
+	filter := fmt.Sprintf("serviceName eq 'Virtual WAN' and armRegionName eq '%s'", getString(values, "location"))
+	pricers := []types.Priceable{hourlyMeter(filter+" and meterName eq 'Standard Hub Unit'", 1)}
+	if units, ok := getFloat(values, "virtual_router_auto_scale_min_capacity"); ok && units > VIRTUAL_HUB_INCLUDED_ROUTING_UNITS {
+		pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'Routing Infrastructure Unit') eq true)", units-VIRTUAL_HUB_INCLUDED_ROUTING_UNITS))
+	}
+	return appendMeters(pricers, usageMeter(usage, "data_processed_gb", filter+" and (contains(meterName,'Data Processed') eq true)"))
+}
+
+// virtualWanGatewayPricers prices a VPN or ExpressRoute gateway in a virtual hub by its scale units, and a VPN gateway
+// by the site connections made to it
+func virtualWanGatewayPricers(graph *planGraph, res *planResource) []types.Priceable {
+	filter := fmt.Sprintf("serviceName eq 'Virtual WAN' and armRegionName eq '%s'", getString(res.Values, "location"))
+	if res.Type == "azurerm_express_route_gateway" {
+		units, ok := getFloat(res.Values, "scale_units")
+		if !ok || units < 1 {
+			units = 1
+		}
+		return []types.Priceable{hourlyMeter(filter+" and (contains(meterName,'ExpressRoute Scale Unit') eq true)", units)}
+	}
+	units, ok := getFloat(res.Values, "scale_unit")
+	if !ok || units < 1 {
+		units = 1
+	}
+	pricers := []types.Priceable{hourlyMeter(filter+" and (contains(meterName,'S2S VPN Scale Unit') eq true)", units)}
+	if connections := float64(len(graph.referencing(res, "azurerm_vpn_gateway_connection", "vpn_gateway_id"))); connections > 0 {
+		pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'S2S VPN Connection Unit') eq true)", connections))
+	}
+	return pricers
+}
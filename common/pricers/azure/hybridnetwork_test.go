@@ -0,0 +1,68 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestExpressRouteSpeed(t *testing.T) {
+	cases := map[float64]string{
+		50:    "50 Mbps",
+		200:   "200 Mbps",
+		1000:  "1 Gbps",
+		2000:  "2 Gbps",
+		10000: "10 Gbps",
+	}
+	for bandwidth, speed := range cases {
+		assert.Equal(t, speed, expressRouteSpeed(bandwidth))
+	}
+}
+
+func TestExpressRouteZone(t *testing.T) {
+	cases := []struct {
+		location string
+		zone     string
+		ok       bool
+	}{
+		{"westeurope", "Zone 1", true},
+		{"East US 2", "Zone 1", true},
+		{"uksouth", "Zone 1", true},
+		{"southeastasia", "Zone 2", true},
+		{"Australia East", "Zone 2", true},
+		{"brazilsouth", "Zone 3", true},
+		{"", "", false},
+		{"moon", "", false},
+	}
+	for _, c := range cases {
+		zone, ok := expressRouteZone(c.location)
+		assert.Equal(t, c.zone, zone, c.location)
+		assert.Equal(t, c.ok, ok, c.location)
+	}
+}
+
+func TestExpressRouteCircuitPricers(t *testing.T) {
+	circuit := func(location, family string) map[string]interface{} {
+		return map[string]interface{}{
+			"location":          location,
+			"bandwidth_in_mbps": 1000.0,
+			"sku":               []interface{}{map[string]interface{}{"tier": "Standard", "family": family}},
+		}
+	}
+	pricers := expressRouteCircuitPricers(circuit("japaneast", "MeteredData"), types.Usage{"outbound_data_gb": 100})
+	if assert.Len(t, pricers, 2) {
+		assert.Contains(t, pricers[0].(*meter).Filter, "location eq 'Zone 2' and skuName eq 'Standard Metered Data'")
+		assert.Contains(t, pricers[0].(*meter).Filter, "'1 Gbps'")
+		assert.Equal(t, types.FixedRange(100), pricers[1].(*meter).Quantity)
+	}
+
+	// unlimited circuits don't pay for their data
+	assert.Len(t, expressRouteCircuitPricers(circuit("westeurope", "UnlimitedData"), types.Usage{"outbound_data_gb": 100}), 1)
+
+	// without a known zone the port fee is spread over every zone
+	pricers = expressRouteCircuitPricers(circuit("moon", "UnlimitedData"), types.Usage{})
+	if assert.Len(t, pricers, 2) {
+		assert.Len(t, pricers[0].(zoneSpread), len(expressRouteZones))
+		assert.IsType(t, note(""), pricers[1])
+	}
+}
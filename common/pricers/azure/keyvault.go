@@ -0,0 +1,40 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+)
+
+// keyVaultPricers prices the operations on a vault from its usage, which is all a vault is billed for besides its
+// HSM-protected keys
+func keyVaultPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	sku := "Standard"
+	if getString(values, "sku_name") == "premium" {
+		sku = "Premium"
+	}
+	filter := fmt.Sprintf("serviceName eq 'Key Vault' and armRegionName eq '%s' and skuName eq '%s'", getString(values, "location"), sku)
+	pricers := appendMeters(nil,
+		usageMeter(usage, "operations", filter+" and meterName eq 'Operations'"),
+		usageMeter(usage, "advanced_key_operations", filter+" and (contains(meterName,'Advanced Key Operations') eq true)"),
+		usageMeter(usage, "certificate_renewals", filter+" and (contains(meterName,'Certificate Renewal') eq true)"),
+	)
+	if len(pricers) == 0 {
+		return []types.Priceable{note("no usage given for this key vault, add operations to a usage profile to price it")}
+	}
+	return pricers
+}
+
+// keyVaultKeyPricers prices an HSM-protected key in a premium vault by the month, with RSA 2048-bit keys cheaper than
+// larger RSA and elliptic curve keys.  Software-protected keys are only billed through the vault's operations.
+func keyVaultKeyPricers(vault *planResource, values map[string]interface{}) []types.Priceable {
+	keyType := getString(values, "key_type")
+	if !strings.HasSuffix(keyType, "-HSM") {
+		return nil
+	}
+	filter := fmt.Sprintf("serviceName eq 'Key Vault' and armRegionName eq '%s' and skuName eq 'Premium' and (contains(meterName,'HSM') eq true)", getString(vault.Values, "location"))
+	if size, _ := getFloat(values, "key_size"); keyType == "RSA-HSM" && (size == 0 || size == 2048) {
+		return []types.Priceable{monthlyMeter(filter+" and (contains(meterName,'2048') eq true)", 1)}
+	}
+	return []types.Priceable{monthlyMeter(filter+" and (contains(meterName,'Advanced Key') eq true) and (contains(meterName,'Operations') eq false)", 1)}
+}
@@ -0,0 +1,44 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+)
+
+// Days of retention included in the price of ingesting data into a workspace
+const LOG_ANALYTICS_INCLUDED_RETENTION_DAYS = 31.0
+
+/*
+logAnalyticsWorkspacePricers prices a workspace from the GB a month it ingests, either pay-as-you-go or against a
+commitment tier when a reservation_capacity_in_gb_per_day is set.  A commitment tier is billed every day whether or not
+it's used, and ingestion beyond it at the tier's effective price per GB.  Data kept beyond the included retention is
+billed for each month it's kept.
+*/
+func logAnalyticsWorkspacePricers(values map[string]interface{}, usage types.Usage, monthHours float64) []types.Priceable {
+	filter := fmt.Sprintf("serviceName eq 'Log Analytics' and armRegionName eq '%s'", getString(values, "location"))
+	ingestion, hasUsage := usage.Get("ingestion_gb")
+	var pricers []types.Priceable
+	commitment, _ := getFloat(values, "reservation_capacity_in_gb_per_day")
+	if sku := getString(values, "sku"); sku == "CapacityReservation" && commitment > 0 {
+		tier := filter + fmt.Sprintf(" and (contains(meterName,'%g GB Commitment Tier') eq true)", commitment)
+		pricers = append(pricers, &meter{Filter: tier, Quantity: types.FixedRange(1), Period: perDay})
+		//The overage is priced as a share of a day's commitment
+		if overage := ingestion - commitment*monthHours/24; overage > 0 {
+			pricers = append(pricers, monthlyMeter(tier, overage/commitment))
+		}
+	} else if sku == "Free" {
+		return []types.Priceable{note("Free workspaces are limited to 500MB a day and 7 days retention")}
+	} else if hasUsage {
+		pricers = append(pricers, tieredMeter(monthlyMeter(filter+" and (contains(meterName,'Data Ingestion') eq true) and (contains(meterName,'Basic') eq false)", ingestion)))
+	}
+
+	if retention, _ := getFloat(values, "retention_in_days"); hasUsage && retention > LOG_ANALYTICS_INCLUDED_RETENTION_DAYS {
+		//Each month's ingestion is kept for the months of retention beyond those included
+		months := (retention - LOG_ANALYTICS_INCLUDED_RETENTION_DAYS) / (monthHours / 24)
+		pricers = append(pricers, monthlyMeter(filter+" and (contains(meterName,'Data Retention') eq true)", ingestion*months))
+	}
+	if !hasUsage {
+		pricers = append(pricers, note("no ingestion_gb usage given for this workspace, its ingestion and retention aren't priced"))
+	}
+	return pricers
+}
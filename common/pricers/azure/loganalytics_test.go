@@ -0,0 +1,53 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestLogAnalyticsWorkspace(t *testing.T) {
+	const monthDays = 730 / 24.0
+	type quantity struct {
+		period meterPeriod
+		amount float64
+	}
+	cases := []struct {
+		name   string
+		values map[string]interface{}
+		usage  types.Usage
+		meters []quantity
+		notes  int
+	}{
+		{"pay-as-you-go", map[string]interface{}{"sku": "PerGB2018"}, types.Usage{"ingestion_gb": 500}, []quantity{{perMonth, 500}}, 0},
+		{"pay-as-you-go without usage", map[string]interface{}{"sku": "PerGB2018", "retention_in_days": 90.0}, types.Usage{}, nil, 1},
+		{"extra retention", map[string]interface{}{"sku": "PerGB2018", "retention_in_days": 90.0}, types.Usage{"ingestion_gb": 500},
+			[]quantity{{perMonth, 500}, {perMonth, 500 * (90 - 31) / monthDays}}, 0},
+		{"included retention", map[string]interface{}{"sku": "PerGB2018", "retention_in_days": 31.0}, types.Usage{"ingestion_gb": 500}, []quantity{{perMonth, 500}}, 0},
+		// a commitment tier is billed each day, and ingestion beyond it as a share of a day's commitment
+		{"commitment tier", map[string]interface{}{"sku": "CapacityReservation", "reservation_capacity_in_gb_per_day": 100.0}, types.Usage{"ingestion_gb": 4000},
+			[]quantity{{perDay, 1}, {perMonth, (4000 - 100*monthDays) / 100}}, 0},
+		{"commitment tier with headroom", map[string]interface{}{"sku": "CapacityReservation", "reservation_capacity_in_gb_per_day": 100.0}, types.Usage{"ingestion_gb": 1000},
+			[]quantity{{perDay, 1}}, 0},
+		{"free", map[string]interface{}{"sku": "Free"}, types.Usage{"ingestion_gb": 10}, nil, 1},
+	}
+	for _, c := range cases {
+		var meters []quantity
+		notes := 0
+		for _, pricer := range logAnalyticsWorkspacePricers(c.values, c.usage, 730) {
+			switch p := pricer.(type) {
+			case *meter:
+				meters = append(meters, quantity{p.Period, p.Quantity.Expected})
+			case note:
+				notes++
+			}
+		}
+		if assert.Len(t, meters, len(c.meters), c.name) {
+			for i := range meters {
+				assert.Equal(t, c.meters[i].period, meters[i].period, c.name)
+				assert.InDelta(t, c.meters[i].amount, meters[i].amount, 0.0001, c.name)
+			}
+		}
+		assert.Equal(t, c.notes, notes, c.name)
+	}
+}
@@ -0,0 +1,83 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strconv"
+	"strings"
+)
+
+// eventHubNamespacePricers prices a namespace's throughput units, or processing units for Premium, along with Capture
+// when any of its event hubs use it and the ingress events in its usage.  Namespaces on a dedicated cluster are paid
+// for by the cluster.
+func eventHubNamespacePricers(graph *planGraph, res *planResource, usage types.Usage) []types.Priceable {
+	sku := getString(res.Values, "sku")
+	capacity, ok := getFloat(res.Values, "capacity")
+	if !ok || capacity < 1 {
+		capacity = 1
+	}
+	filter := fmt.Sprintf("serviceName eq 'Event Hubs' and armRegionName eq '%s' and skuName eq '%s'", getString(res.Values, "location"), sku)
+	unit := "Throughput Unit"
+	if sku == "Premium" {
+		unit = "Processing Unit"
+	}
+	pricers := []types.Priceable{hourlyMeter(filter+fmt.Sprintf(" and (contains(meterName,'%s') eq true)", unit), capacity)}
+	//namespace_name was replaced by namespace_id in v4 of the provider
+	hubs := append(graph.referencing(res, "azurerm_eventhub", "namespace_name"), graph.referencing(res, "azurerm_eventhub", "namespace_id")...)
+	for _, hub := range hubs {
+		if capture := getBlock(hub.Values, "capture_description"); capture != nil && getBool(capture, "enabled") && sku == "Standard" {
+			pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'Capture') eq true)", capacity))
+			break
+		}
+	}
+	//Premium namespaces include their ingress in the processing units
+	if sku != "Premium" {
+		pricers = appendMeters(pricers, usageMeter(usage, "ingress_events", filter+" and (contains(meterName,'Ingress Events') eq true)"))
+	}
+	return pricers
+}
+
+// eventHubClusterPricers prices a dedicated cluster by the capacity units in its sku_name, e.g. Dedicated_1
+func eventHubClusterPricers(values map[string]interface{}) []types.Priceable {
+	units := 1.0
+	if parts := strings.SplitN(getString(values, "sku_name"), "_", 2); len(parts) == 2 {
+		if capacity, err := strconv.ParseFloat(parts[1], 64); err == nil && capacity > 0 {
+			units = capacity
+		}
+	}
+	return []types.Priceable{hourlyMeter(fmt.Sprintf("serviceName eq 'Event Hubs' and armRegionName eq '%s' and skuName eq 'Dedicated' and (contains(meterName,'Capacity Unit') eq true)",
+		getString(values, "location")), units)}
+}
+
+// serviceBusNamespacePricers prices the messaging units of a Premium namespace, or the base charge of a Standard one,
+// plus the operations in the usage of Basic and Standard namespaces
+func serviceBusNamespacePricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	sku := getString(values, "sku")
+	filter := fmt.Sprintf("serviceName eq 'Service Bus' and armRegionName eq '%s' and skuName eq '%s'", getString(values, "location"), sku)
+	switch sku {
+	case "Premium":
+		units, ok := getFloat(values, "capacity")
+		if !ok || units < 1 {
+			units = 1
+		}
+		return []types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Messaging Unit') eq true)", units)}
+	case "Standard":
+		//The operations included in the base charge are the bottom tier of the operations meter
+		return appendMeters([]types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Base Unit') eq true)", 1)},
+			tieredMeter(usageMeter(usage, "operations", filter+" and (contains(meterName,'Messaging Operations') eq true)")))
+	}
+	if operations := usageMeter(usage, "operations", filter+" and (contains(meterName,'Messaging Operations') eq true)"); operations != nil {
+		return []types.Priceable{operations}
+	}
+	return []types.Priceable{note("no operations usage given for this Basic namespace, add operations to a usage profile to price it")}
+}
+
+// eventGridTopicPricers prices the operations published to a topic or domain, the first 100,000 of which are free
+func eventGridTopicPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	operations := tieredMeter(usageMeter(usage, "operations", fmt.Sprintf("serviceName eq 'Event Grid' and armRegionName eq '%s' and (contains(meterName,'Operations') eq true)",
+		getString(values, "location"))))
+	if operations == nil {
+		return []types.Priceable{note("no operations usage given for this topic, add operations to a usage profile to price it")}
+	}
+	return []types.Priceable{operations}
+}
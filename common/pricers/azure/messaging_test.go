@@ -0,0 +1,63 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestEventHubClusterUnits(t *testing.T) {
+	cases := []struct {
+		skuName string
+		units   float64
+	}{
+		{"Dedicated_1", 1},
+		{"Dedicated_2", 2},
+		{"Dedicated_10", 10},
+		{"Dedicated", 1},
+		{"Dedicated_0", 1},
+		{"Dedicated_x", 1},
+	}
+	for _, c := range cases {
+		pricers := eventHubClusterPricers(map[string]interface{}{"sku_name": c.skuName, "location": "eastus"})
+		if !assert.Len(t, pricers, 1, c.skuName) {
+			continue
+		}
+		m := pricers[0].(*meter)
+		assert.Equal(t, types.FixedRange(c.units), m.Quantity, c.skuName)
+		assert.Equal(t, perHour, m.Period, c.skuName)
+		assert.Contains(t, m.Filter, "armRegionName eq 'eastus'", c.skuName)
+	}
+}
+
+func TestEventHubNamespaceCapture(t *testing.T) {
+	capture := map[string]interface{}{"capture_description": []interface{}{map[string]interface{}{"enabled": true}}}
+	cases := []struct {
+		name    string
+		sku     string
+		hubs    []*planResource
+		meters  int
+		ingress bool
+	}{
+		{"standard without capture", "Standard", []*planResource{
+			testResource("azurerm_eventhub.a", nil, map[string][]string{"namespace_name": {"azurerm_eventhub_namespace.ns"}}),
+		}, 2, true},
+		{"standard with capture on two hubs", "Standard", []*planResource{
+			testResource("azurerm_eventhub.a", capture, map[string][]string{"namespace_name": {"azurerm_eventhub_namespace.ns"}}),
+			testResource("azurerm_eventhub.b", capture, map[string][]string{"namespace_id": {"azurerm_eventhub_namespace.ns"}}),
+		}, 3, true},
+		{"premium includes ingress and capture", "Premium", []*planResource{
+			testResource("azurerm_eventhub.a", capture, map[string][]string{"namespace_id": {"azurerm_eventhub_namespace.ns"}}),
+		}, 1, false},
+	}
+	for _, c := range cases {
+		ns := testResource("azurerm_eventhub_namespace.ns", map[string]interface{}{"sku": c.sku, "capacity": 2.0, "location": "eastus"}, nil)
+		pricers := eventHubNamespacePricers(testGraph(append(c.hubs, ns)...), ns, types.Usage{"ingress_events": 5000000})
+		if !assert.Len(t, pricers, c.meters, c.name) {
+			continue
+		}
+		assert.Equal(t, types.FixedRange(2), pricers[0].(*meter).Quantity, c.name)
+		last := pricers[len(pricers)-1].(*meter)
+		assert.Equal(t, c.ingress, last.Period == perMonth, c.name)
+	}
+}
@@ -0,0 +1,167 @@
+package azure
+
+import (
+	"context"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"k8s.io/klog/v2"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type meterPeriod int
+
+const (
+	perHour meterPeriod = iota
+	perDay
+	perMonth
+)
+
+/*
+A meter is a single charge against the Azure Retail Prices API, for resources that are billed on more than the one
+price the VM and disk pricers look up, e.g. a gateway billed per hour plus per GB it processes.  The Quantity is in
+whole units of the meter's unitOfMeasure, so 20000 transactions against a "10K" meter rather than 2.
+*/
+type meter struct {
+	//OData filter selecting the meter, without the priceType which is picked from the PricingScheme
+	Filter   string
+	Quantity types.Range
+	Period   meterPeriod
+	//Price the quantity across the meter's tierMinimumUnits rather than all at the first tier's price
+	Tiered bool
+	//Units of a tiered meter already used by other resources, so that a free grant or discount they share is only
+	//applied once
+	TierOffset float64
+	//Look for a reservation price when a reservation scheme is chosen, falling back to pay-as-you-go without one
+	Reservable    bool
+	PricingScheme PricingScheme
+}
+
+// retailQuery is a complete OData filter against the Azure Retail Prices API
+type retailQuery string
+
+func (q retailQuery) GenerateQuery(context.Context) string {
+	return string(q)
+}
+
+func hourlyMeter(filter string, quantity float64) *meter {
+	return &meter{Filter: filter, Quantity: types.FixedRange(quantity), Period: perHour}
+}
+
+func monthlyMeter(filter string, quantity float64) *meter {
+	return &meter{Filter: filter, Quantity: types.FixedRange(quantity), Period: perMonth}
+}
+
+func (m *meter) GetHourlyPrice(ctx context.Context) types.Range {
+	if m.Reservable && (m.PricingScheme == Reservation1Yr || m.PricingScheme == Reservation3Yr) {
+		if price, ok := m.reservationPrice(ctx); ok {
+			return m.Quantity.Scale(price)
+		}
+	}
+	query := retailQuery(m.Filter + " and priceType eq 'Consumption'")
+	resp, err := types.ExecuteAzurePriceQuery(ctx, query)
+	if err != nil {
+		klog.Error(err)
+		return types.Range{}
+	}
+	if len(resp.Items) == 0 {
+		klog.Errorf("no price found for %s", query)
+		return types.Range{}
+	}
+	cost := types.Range{
+		Min:      m.cost(resp.Items, m.Quantity.Min),
+		Expected: m.cost(resp.Items, m.Quantity.Expected),
+		Max:      m.cost(resp.Items, m.Quantity.Max),
+	}
+	switch m.Period {
+	case perDay:
+		return cost.Scale(1 / 24.0)
+	case perMonth:
+		return cost.Scale(1 / types.MonthHours(ctx))
+	}
+	return cost
+}
+
+// cost prices a quantity of the meter, either entirely at the first item's price or across its pricing tiers on top of
+// the units other resources have already used
+func (m *meter) cost(items []types.AzurePricingApiItem, quantity float64) float64 {
+	if !m.Tiered {
+		return items[0].UnitPrice / unitsOfMeasure(items[0].UnitOfMeasure) * quantity
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].TierMinimumUnits < items[j].TierMinimumUnits })
+	return tieredCost(items, m.TierOffset+quantity) - tieredCost(items, m.TierOffset)
+}
+
+// tieredCost prices a quantity across pricing tiers sorted by their tierMinimumUnits
+func tieredCost(items []types.AzurePricingApiItem, quantity float64) float64 {
+	units := quantity / unitsOfMeasure(items[0].UnitOfMeasure)
+	var total float64
+	for i, item := range items {
+		if units <= item.TierMinimumUnits {
+			break
+		}
+		tierUnits := units - item.TierMinimumUnits
+		if i+1 < len(items) && units > items[i+1].TierMinimumUnits {
+			tierUnits = items[i+1].TierMinimumUnits - item.TierMinimumUnits
+		}
+		total += tierUnits * item.UnitPrice
+	}
+	return total
+}
+
+// reservationPrice is the hourly price of one unit reserved for the chosen term.  Reservation unit prices are for the
+// whole term, and we can't filter on 'reservationTerm' in the ODATA query, so the term is matched here.
+func (m *meter) reservationPrice(ctx context.Context) (float64, bool) {
+	resp, err := types.ExecuteAzurePriceQuery(ctx, retailQuery(m.Filter+" and priceType eq 'Reservation'"))
+	if err != nil {
+		klog.Error(err)
+		return 0, false
+	}
+	for _, item := range resp.Items {
+		if item.ReservationTerm == "1 Year" && m.PricingScheme == Reservation1Yr {
+			return item.UnitPrice / types.YEAR_HOURS, true
+		} else if item.ReservationTerm == "3 Years" && m.PricingScheme == Reservation3Yr {
+			return item.UnitPrice / (3.0 * types.YEAR_HOURS), true
+		}
+	}
+	return 0, false
+}
+
+// unitsOfMeasure is how many units a price is quoted for, e.g. 10000 for "10K", 100 for "100 Hours" and 1 for "1 GB/Month"
+func unitsOfMeasure(uom string) float64 {
+	fields := strings.Fields(strings.SplitN(uom, "/", 2)[0])
+	if len(fields) == 0 {
+		return 1
+	}
+	number := fields[0]
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier, number = 1000, strings.TrimSuffix(number, "K")
+	case strings.HasSuffix(number, "M"):
+		multiplier, number = 1000000, strings.TrimSuffix(number, "M")
+	}
+	units, err := strconv.ParseFloat(number, 64)
+	if err != nil || units == 0 {
+		return 1
+	}
+	return units * multiplier
+}
+
+// scheduledMeter is a meter that is only billed while its resource is running, so an uptime profile scales it
+type scheduledMeter struct {
+	*meter
+	Uptime *types.Range
+}
+
+func (m *scheduledMeter) GetHourlyPrice(ctx context.Context) types.Range {
+	price := m.meter.GetHourlyPrice(ctx)
+	if m.Uptime != nil {
+		return price.Multiply(*m.Uptime)
+	}
+	return price
+}
+
+func (m *scheduledMeter) setUptime(uptime types.Range) {
+	m.Uptime = &uptime
+}
@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestTieredMeterCost(t *testing.T) {
+	// the first million executions are free, the rest $0.20 per million
+	executions := []types.AzurePricingApiItem{
+		{UnitPrice: 0.2, UnitOfMeasure: "10", TierMinimumUnits: 100000},
+		{UnitPrice: 0, UnitOfMeasure: "10", TierMinimumUnits: 0},
+	}
+	cases := []struct {
+		name     string
+		tiered   bool
+		offset   float64
+		quantity float64
+		cost     float64
+	}{
+		{"within the free tier", true, 0, 500000, 0},
+		{"beyond the free tier", true, 0, 3000000, 0.2 * 200000},
+		{"free tier used by another resource", true, 1000000, 500000, 0.2 * 50000},
+		{"free tier partly used by another resource", true, 800000, 500000, 0.2 * 30000},
+		// without tiers the first item, here the free one once they're sorted, prices everything
+		{"not tiered", false, 0, 3000000, 0},
+	}
+	for _, c := range cases {
+		items := append([]types.AzurePricingApiItem{}, executions...)
+		if !c.tiered {
+			items = []types.AzurePricingApiItem{executions[1], executions[0]}
+		}
+		m := &meter{Tiered: c.tiered, TierOffset: c.offset}
+		assert.InDelta(t, c.cost, m.cost(items, c.quantity), 0.0001, c.name)
+	}
+}
+
+func TestFunctionAppFreeGrant(t *testing.T) {
+	used := map[string]float64{}
+	first := functionAppConsumptionPricers("eastus", types.Usage{"executions": 700000, "gb_seconds": 100000}, used)
+	second := functionAppConsumptionPricers("eastus", types.Usage{"executions": 700000}, used)
+	if assert.Len(t, first, 2) && assert.Len(t, second, 1) {
+		assert.True(t, first[0].(*meter).Tiered)
+		assert.Equal(t, 0.0, first[0].(*meter).TierOffset)
+		assert.Equal(t, 0.0, first[1].(*meter).TierOffset)
+		// the second app's executions are priced after the first's have used up part of the grant
+		assert.Equal(t, 700000.0, second[0].(*meter).TierOffset)
+		assert.Equal(t, types.FixedRange(700000), second[0].(*meter).Quantity)
+	}
+	assert.Equal(t, map[string]float64{"executions": 1400000, "gb_seconds": 100000}, used)
+
+	pricers := functionAppConsumptionPricers("eastus", types.Usage{}, used)
+	if assert.Len(t, pricers, 1) {
+		assert.IsType(t, note(""), pricers[0])
+	}
+}
@@ -0,0 +1,86 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"math"
+)
+
+// The first five load balancing and outbound rules of a Standard load balancer are covered by its hourly price
+const LB_INCLUDED_RULES = 5.0
+
+// Rules that count towards the rules billed on a Standard load balancer.  Inbound NAT rules are free.
+var lbRuleTypes = []string{"azurerm_lb_rule", "azurerm_lb_outbound_rule"}
+
+// publicIpPricers prices an azurerm_public_ip by its sku, allocation method and IP version.  Addresses taken from a
+// public IP prefix are paid for by the prefix.
+func publicIpPricers(values map[string]interface{}) []types.Priceable {
+	sku := getString(values, "sku")
+	if sku == "" {
+		sku = "Basic"
+	}
+	if getString(values, "sku_tier") == "Global" {
+		sku = "Global"
+	}
+	method := getString(values, "allocation_method")
+	if method == "" {
+		method = "Dynamic"
+	}
+	pricers := []types.Priceable{publicIpMeter(getString(values, "location"), sku, method, getString(values, "ip_version") == "IPv6", 1)}
+	//DDoS IP Protection is billed per address, rather than through a DDoS protection plan on its virtual network
+	if getString(values, "ddos_protection_mode") == "Enabled" {
+		pricers = append(pricers, monthlyMeter(fmt.Sprintf("serviceName eq 'Azure DDOS Protection' and armRegionName eq '%s' and (contains(meterName,'IP Protection') eq true)",
+			getString(values, "location")), 1))
+	}
+	return pricers
+}
+
+func publicIpMeter(location, sku, method string, ipv6 bool, count float64) *meter {
+	return hourlyMeter(fmt.Sprintf(
+		"serviceName eq 'Virtual Network' and productName eq 'IP Addresses' and armRegionName eq '%s' and skuName eq '%s' and (contains(meterName,'%s') eq true) and (contains(meterName,'Prefix') eq false) and (contains(meterName,'IPv6') eq %t)",
+		location, sku, method, ipv6), count)
+}
+
+// publicIpPrefixPricers prices each address in a public IP prefix, e.g. 16 for the default /28
+func publicIpPrefixPricers(values map[string]interface{}) []types.Priceable {
+	length, ok := getFloat(values, "prefix_length")
+	if !ok {
+		length = 28
+	}
+	bits, ipv6 := 32.0, getString(values, "ip_version") == "IPv6"
+	if ipv6 {
+		bits = 128
+	}
+	return []types.Priceable{hourlyMeter(fmt.Sprintf(
+		"serviceName eq 'Virtual Network' and productName eq 'IP Addresses' and armRegionName eq '%s' and skuName eq 'Standard' and (contains(meterName,'Prefix') eq true) and (contains(meterName,'IPv6') eq %t)",
+		getString(values, "location"), ipv6), math.Pow(2, bits-length))}
+}
+
+// loadBalancerPricers prices a Standard load balancer for its rules, the first five of which are covered by its hourly
+// price, and the data it processes.  A load balancer without any rules isn't billed.
+func loadBalancerPricers(graph *planGraph, res *planResource, usage types.Usage) []types.Priceable {
+	var rules float64
+	for _, ruleType := range lbRuleTypes {
+		rules += float64(len(graph.referencing(res, ruleType, "loadbalancer_id")))
+	}
+	filter := fmt.Sprintf("serviceName eq 'Load Balancer' and armRegionName eq '%s' and skuName eq 'Standard'", getString(res.Values, "location"))
+	var pricers []types.Priceable
+	if rules > 0 {
+		pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'Included') eq true)", 1))
+	}
+	if rules > LB_INCLUDED_RULES {
+		pricers = append(pricers, hourlyMeter(filter+" and (contains(meterName,'Overage') eq true)", rules-LB_INCLUDED_RULES))
+	}
+	pricers = appendMeters(pricers, usageMeter(usage, "data_processed_gb", filter+" and (contains(meterName,'Data Processed') eq true)"))
+	if len(pricers) == 0 {
+		return []types.Priceable{note("no rules are attached to this load balancer, it isn't billed until it has some")}
+	}
+	return pricers
+}
+
+// natGatewayPricers prices a NAT gateway's hours and the data it processes
+func natGatewayPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	filter := fmt.Sprintf("serviceName eq 'NAT Gateway' and armRegionName eq '%s'", getString(values, "location"))
+	return appendMeters([]types.Priceable{hourlyMeter(filter+" and (contains(meterName,'Gateway') eq true)", 1)},
+		usageMeter(usage, "data_processed_gb", filter+" and (contains(meterName,'Data Processed') eq true)"))
+}
@@ -0,0 +1,46 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestPublicIpPrefixAddresses(t *testing.T) {
+	cases := []struct {
+		values    map[string]interface{}
+		addresses float64
+		ipv6      string
+	}{
+		{map[string]interface{}{}, 16, "eq false"},
+		{map[string]interface{}{"prefix_length": 31.0}, 2, "eq false"},
+		{map[string]interface{}{"prefix_length": 24.0, "ip_version": "IPv4"}, 256, "eq false"},
+		{map[string]interface{}{"prefix_length": 127.0, "ip_version": "IPv6"}, 2, "eq true"},
+		{map[string]interface{}{"prefix_length": 124.0, "ip_version": "IPv6"}, 16, "eq true"},
+	}
+	for _, c := range cases {
+		m := publicIpPrefixPricers(c.values)[0].(*meter)
+		assert.Equal(t, types.FixedRange(c.addresses), m.Quantity, "%v", c.values)
+		assert.Contains(t, m.Filter, "(contains(meterName,'IPv6') "+c.ipv6+")", "%v", c.values)
+	}
+}
+
+func TestPublicIpSku(t *testing.T) {
+	cases := []struct {
+		values  map[string]interface{}
+		filter  []string
+		pricers int
+	}{
+		{map[string]interface{}{}, []string{"skuName eq 'Basic'", "'Dynamic'"}, 1},
+		{map[string]interface{}{"sku": "Standard", "allocation_method": "Static"}, []string{"skuName eq 'Standard'", "'Static'"}, 1},
+		{map[string]interface{}{"sku": "Standard", "sku_tier": "Global", "allocation_method": "Static"}, []string{"skuName eq 'Global'"}, 1},
+		{map[string]interface{}{"sku": "Standard", "allocation_method": "Static", "ddos_protection_mode": "Enabled"}, []string{"skuName eq 'Standard'"}, 2},
+	}
+	for _, c := range cases {
+		pricers := publicIpPricers(c.values)
+		assert.Len(t, pricers, c.pricers, "%v", c.values)
+		for _, part := range c.filter {
+			assert.Contains(t, pricers[0].(*meter).Filter, part, "%v", c.values)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package azure
+
+import (
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"regexp"
+	"strings"
+)
+
+var instanceKeyRegex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// planResource is a single resource change from the plan along with the references its configuration makes to other
+// resources, keyed by the (dot separated) attribute that makes the reference, e.g. "kubernetes_cluster_id".
+type planResource struct {
+	Address       string
+	ConfigAddress string
+	Type          string
+	Provider      string
+	Values        map[string]interface{}
+	References    map[string][]string
+}
+
+// planGraph indexes the resources in a plan so that pricers can find the resources they are linked to
+type planGraph struct {
+	Resources       []*planResource
+	byConfigAddress map[string][]*planResource
+}
+
+func newPlanGraph(pf types.PlanFile) *planGraph {
+	references := map[string]map[string][]string{}
+	collectModuleReferences("", pf.Configuration.RootModule, references)
+
+	g := &planGraph{byConfigAddress: map[string][]*planResource{}}
+	for _, change := range pf.ResourceChanges {
+		values, ok := change.Change.After.(map[string]interface{})
+		if !ok {
+			//resources being destroyed have nothing to price
+			continue
+		}
+		res := &planResource{
+			Address:       change.Address,
+			ConfigAddress: configAddress(change.Address),
+			Type:          change.Type,
+			Provider:      change.Provider,
+			Values:        values,
+		}
+		res.References = references[res.ConfigAddress]
+		g.Resources = append(g.Resources, res)
+		g.byConfigAddress[res.ConfigAddress] = append(g.byConfigAddress[res.ConfigAddress], res)
+	}
+	return g
+}
+
+// referencedBy returns the resources that the given attribute of res refers to
+func (g *planGraph) referencedBy(res *planResource, attribute string) []*planResource {
+	var found []*planResource
+	for _, address := range res.References[attribute] {
+		found = append(found, g.byConfigAddress[address]...)
+	}
+	return found
+}
+
+// linkedResource returns the first resource of resourceType that any of the given attributes of res refer to, or nil
+// when it isn't part of the plan
+func (g *planGraph) linkedResource(res *planResource, resourceType string, attributes ...string) *planResource {
+	for _, attribute := range attributes {
+		for _, other := range g.referencedBy(res, attribute) {
+			if other.Type == resourceType {
+				return other
+			}
+		}
+	}
+	return nil
+}
+
+// referencing returns the resources of resourceType whose attribute refers to res
+func (g *planGraph) referencing(res *planResource, resourceType, attribute string) []*planResource {
+	var found []*planResource
+	for _, other := range g.Resources {
+		if other.Type != resourceType {
+			continue
+		}
+		for _, address := range other.References[attribute] {
+			if address == res.ConfigAddress {
+				found = append(found, other)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// configAddress strips the count/for_each instance keys from a resource address so that it matches the address used
+// in the plan configuration, e.g. module.app["a"].azurerm_lb.main[0] becomes module.app.azurerm_lb.main
+func configAddress(address string) string {
+	return instanceKeyRegex.ReplaceAllString(address, "")
+}
+
+func collectModuleReferences(prefix string, module types.ConfigModule, references map[string]map[string][]string) {
+	for _, resource := range module.Resources {
+		refs := map[string][]string{}
+		for attribute, expression := range resource.Expressions {
+			collectExpressionReferences(prefix, attribute, expression, refs)
+		}
+		references[prefix+resource.Address] = refs
+	}
+	for name, call := range module.ModuleCalls {
+		collectModuleReferences(prefix+"module."+name+".", call.Module, references)
+	}
+}
+
+// collectExpressionReferences walks an expression, which is either a single attribute expression or a list of nested
+// blocks, recording the resources it refers to under the dot separated attribute path
+func collectExpressionReferences(prefix, path string, expression interface{}, refs map[string][]string) {
+	switch e := expression.(type) {
+	case []interface{}:
+		for _, block := range e {
+			collectExpressionReferences(prefix, path, block, refs)
+		}
+	case map[string]interface{}:
+		if list, ok := e["references"].([]interface{}); ok {
+			for _, r := range list {
+				if address := referenceAddress(prefix, r); address != "" && !containsString(refs[path], address) {
+					refs[path] = append(refs[path], address)
+				}
+			}
+			return
+		}
+		if _, ok := e["constant_value"]; ok {
+			return
+		}
+		for attribute, nested := range e {
+			collectExpressionReferences(prefix, path+"."+attribute, nested, refs)
+		}
+	}
+}
+
+// referenceAddress turns a reference such as azurerm_kubernetes_cluster.example.id into the configuration address of
+// the resource it refers to.  References to variables, locals, data sources and module outputs are ignored.
+func referenceAddress(prefix string, reference interface{}) string {
+	r, _ := reference.(string)
+	parts := strings.Split(r, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "var", "local", "data", "module", "each", "count", "path", "self", "terraform":
+		return ""
+	}
+	return prefix + parts[0] + "." + configAddress(parts[1])
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
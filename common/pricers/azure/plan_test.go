@@ -0,0 +1,27 @@
+package azure
+
+import "strings"
+
+// testResource is a resource at the given address in a test graph, with the references its configuration makes keyed by
+// attribute
+func testResource(address string, values map[string]interface{}, references map[string][]string) *planResource {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return &planResource{
+		Address:       address,
+		ConfigAddress: configAddress(address),
+		Type:          strings.SplitN(configAddress(address), ".", 2)[0],
+		Values:        values,
+		References:    references,
+	}
+}
+
+// testGraph indexes resources the way newPlanGraph does those of a plan
+func testGraph(resources ...*planResource) *planGraph {
+	g := &planGraph{Resources: resources, byConfigAddress: map[string][]*planResource{}}
+	for _, res := range resources {
+		g.byConfigAddress[res.ConfigAddress] = append(g.byConfigAddress[res.ConfigAddress], res)
+	}
+	return g
+}
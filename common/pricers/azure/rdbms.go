@@ -0,0 +1,137 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tier prefixes of a PostgreSQL or MySQL sku_name.  Single servers call their smallest tier Basic rather than Burstable.
+var rdbmsTiers = map[string]string{
+	"B":  "Burstable",
+	"GP": "General Purpose",
+	"MO": "Memory Optimized",
+}
+
+// A flexible server size such as D4s_v3, split into the family, the vCores and the capabilities and version that make
+// up the series, Dsv3
+var flexibleServerSizeRegex = regexp.MustCompile(`^([A-Z]+)(\d+)([a-z]*)_(v\d)$`)
+
+/*
+A DatabaseServer is an Azure Database for PostgreSQL or MySQL server, either a flexible server with a sku_name made up
+of a tier and a VM size, e.g. GP_Standard_D4s_v3, or an older single server with a tier, hardware generation and number
+of vCores, e.g. GP_Gen5_4.
+*/
+type DatabaseServer struct {
+	//Azure Database for PostgreSQL or Azure Database for MySQL
+	Service          string
+	Location         string
+	Flexible         bool
+	SkuName          string
+	StorageGb        float64
+	HighAvailability bool
+	PricingScheme    PricingScheme
+}
+
+func newDatabaseServer(res *planResource, priceType PricingScheme) *DatabaseServer {
+	s := &DatabaseServer{
+		Service:       "Azure Database for PostgreSQL",
+		Location:      getString(res.Values, "location"),
+		Flexible:      strings.HasSuffix(res.Type, "_flexible_server"),
+		SkuName:       getString(res.Values, "sku_name"),
+		PricingScheme: priceType,
+	}
+	if strings.HasPrefix(res.Type, "azurerm_mysql") {
+		s.Service = "Azure Database for MySQL"
+	}
+	storageMb, ok := getFloat(res.Values, "storage_mb")
+	if !ok {
+		//MySQL flexible servers size their storage in GB, and single servers used to nest it in a storage_profile
+		if storage := getBlock(res.Values, "storage"); storage != nil {
+			if gb, ok := getFloat(storage, "size_gb"); ok {
+				storageMb = gb * 1024
+			}
+		} else if profile := getBlock(res.Values, "storage_profile"); profile != nil {
+			storageMb, _ = getFloat(profile, "storage_mb")
+		}
+	}
+	s.StorageGb = storageMb / 1024
+	if ha := getBlock(res.Values, "high_availability"); ha != nil && getString(ha, "mode") != "" {
+		s.HighAvailability = true
+	}
+	return s
+}
+
+func (s *DatabaseServer) filter() string {
+	kind := "Single Server"
+	if s.Flexible {
+		kind = "Flexible Server"
+	}
+	return fmt.Sprintf("serviceName eq '%s' and armRegionName eq '%s' and (contains(productName,'%s') eq true)", s.Service, s.Location, kind)
+}
+
+// pricers prices the server's compute, doubled for a high availability standby, its storage, and any backups kept
+// beyond the free allowance of as much backup storage as the server has provisioned
+func (s *DatabaseServer) pricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	compute, ok := s.compute()
+	if !ok {
+		return nil
+	}
+	if s.HighAvailability {
+		compute.Quantity = compute.Quantity.Scale(2)
+	}
+	pricers := []types.Priceable{compute}
+	if s.StorageGb > 0 {
+		pricers = append(pricers, monthlyMeter(s.filter()+" and (contains(productName,'Storage') eq true) and (contains(meterName,'Data Stored') eq true)", s.StorageGb))
+	}
+	backupFilter := s.filter() + " and (contains(meterName,'Backup') eq true)"
+	if backup, ok := usage.Get("backup_storage_gb"); ok {
+		if excess := backup - s.StorageGb; excess > 0 {
+			pricers = append(pricers, monthlyMeter(backupFilter, excess))
+		}
+	} else if days, _ := getFloat(values, "backup_retention_days"); days > 7 {
+		pricers = append(pricers, note(fmt.Sprintf("backups kept for %g days may need more than the free backup storage, add backup_storage_gb usage to price them", days)))
+	}
+	return pricers
+}
+
+// compute is the meter for the server's vCores, or its whole instance for burstable sizes
+func (s *DatabaseServer) compute() (*meter, bool) {
+	parts := strings.Split(s.SkuName, "_")
+	tier, ok := rdbmsTiers[parts[0]]
+	if !ok || len(parts) < 3 {
+		return nil, false
+	}
+	m := &meter{Period: perHour, Reservable: true, PricingScheme: s.PricingScheme}
+	if !s.Flexible {
+		if tier == "Burstable" {
+			tier = "Basic"
+		}
+		vcores, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return nil, false
+		}
+		m.Filter = s.filter() + fmt.Sprintf(" and (contains(productName,'%s') eq true) and (contains(productName,'Compute %s') eq true)", tier, parts[1])
+		m.Quantity = types.FixedRange(vcores)
+		return m, true
+	}
+
+	size := strings.Join(parts[2:], "_")
+	if tier == "Burstable" {
+		//Burstable sizes have a price per instance, e.g. B1ms is priced as B1MS
+		m.Filter = s.filter() + fmt.Sprintf(" and (contains(productName,'Burstable') eq true) and skuName eq '%s'", strings.ToUpper(size))
+		m.Quantity = types.FixedRange(1)
+		return m, true
+	}
+	match := flexibleServerSizeRegex.FindStringSubmatch(size)
+	if match == nil {
+		return nil, false
+	}
+	vcores, _ := strconv.ParseFloat(match[2], 64)
+	series := match[1] + match[3] + match[4]
+	m.Filter = s.filter() + fmt.Sprintf(" and (contains(productName,'%s') eq true) and (contains(productName,'%s') eq true) and meterName eq 'vCore'", tier, series)
+	m.Quantity = types.FixedRange(vcores)
+	return m, true
+}
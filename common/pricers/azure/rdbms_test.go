@@ -0,0 +1,100 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestDatabaseServerCompute(t *testing.T) {
+	cases := []struct {
+		resourceType string
+		skuName      string
+		vcores       float64
+		// parts of the filter that pick the right meter
+		filter []string
+	}{
+		{"azurerm_postgresql_flexible_server", "GP_Standard_D4s_v3", 4, []string{"'Flexible Server'", "'General Purpose'", "'Dsv3'", "meterName eq 'vCore'"}},
+		{"azurerm_mysql_flexible_server", "MO_Standard_E16ds_v4", 16, []string{"Azure Database for MySQL", "'Memory Optimized'", "'Edsv4'"}},
+		{"azurerm_postgresql_flexible_server", "B_Standard_B1ms", 1, []string{"'Burstable'", "skuName eq 'B1MS'"}},
+		{"azurerm_postgresql_server", "GP_Gen5_8", 8, []string{"'Single Server'", "'General Purpose'", "'Compute Gen5'"}},
+		{"azurerm_mysql_server", "B_Gen5_2", 2, []string{"'Basic'", "'Compute Gen5'"}},
+	}
+	for _, c := range cases {
+		server := newDatabaseServer(&planResource{Type: c.resourceType, Values: map[string]interface{}{"sku_name": c.skuName, "location": "eastus"}}, Consumption)
+		compute, ok := server.compute()
+		if !assert.True(t, ok, c.skuName) {
+			continue
+		}
+		assert.Equal(t, types.FixedRange(c.vcores), compute.Quantity, c.skuName)
+		assert.True(t, compute.Reservable, c.skuName)
+		for _, part := range c.filter {
+			assert.Contains(t, compute.Filter, part, c.skuName)
+		}
+	}
+
+	for _, skuName := range []string{"", "XX_Standard_D4s_v3", "GP_Standard", "GP_Standard_D4s", "GP_Gen5_many"} {
+		server := newDatabaseServer(&planResource{Type: "azurerm_postgresql_server", Values: map[string]interface{}{"sku_name": skuName}}, Consumption)
+		server.Flexible = skuName == "GP_Standard_D4s"
+		_, ok := server.compute()
+		assert.False(t, ok, skuName)
+	}
+}
+
+func TestDatabaseServerStorage(t *testing.T) {
+	cases := []struct {
+		resourceType string
+		values       map[string]interface{}
+		storageGb    float64
+	}{
+		{"azurerm_postgresql_flexible_server", map[string]interface{}{"storage_mb": 131072.0}, 128},
+		{"azurerm_mysql_flexible_server", map[string]interface{}{"storage": []interface{}{map[string]interface{}{"size_gb": 64.0}}}, 64},
+		{"azurerm_mysql_server", map[string]interface{}{"storage_profile": []interface{}{map[string]interface{}{"storage_mb": 5120.0}}}, 5},
+		{"azurerm_postgresql_server", map[string]interface{}{}, 0},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.storageGb, newDatabaseServer(&planResource{Type: c.resourceType, Values: c.values}, Consumption).StorageGb, c.resourceType)
+	}
+}
+
+func TestDatabaseServerPricers(t *testing.T) {
+	values := map[string]interface{}{
+		"sku_name":              "GP_Standard_D2s_v3",
+		"storage_mb":            32768.0,
+		"backup_retention_days": 14.0,
+		"high_availability":     []interface{}{map[string]interface{}{"mode": "ZoneRedundant"}},
+	}
+	server := newDatabaseServer(&planResource{Type: "azurerm_postgresql_flexible_server", Values: values}, Consumption)
+	assert.True(t, server.HighAvailability)
+
+	cases := []struct {
+		usage types.Usage
+		// the standby doubles the compute, storage is always billed, and only backups beyond the 32GB of storage are
+		quantities []float64
+		notes      int
+	}{
+		{types.Usage{}, []float64{4, 32}, 1},
+		{types.Usage{"backup_storage_gb": 20}, []float64{4, 32}, 0},
+		{types.Usage{"backup_storage_gb": 100}, []float64{4, 32, 68}, 0},
+	}
+	for _, c := range cases {
+		var quantities []float64
+		notes := 0
+		for _, pricer := range server.pricers(values, c.usage) {
+			switch p := pricer.(type) {
+			case *meter:
+				quantities = append(quantities, p.Quantity.Expected)
+			case note:
+				notes++
+			}
+		}
+		assert.Equal(t, c.quantities, quantities, "%v", c.usage)
+		assert.Equal(t, c.notes, notes, "%v", c.usage)
+	}
+
+	values["high_availability"] = []interface{}{}
+	server = newDatabaseServer(&planResource{Type: "azurerm_postgresql_flexible_server", Values: values}, Consumption)
+	compute, _ := server.compute()
+	assert.False(t, server.HighAvailability)
+	assert.Equal(t, types.FixedRange(2), compute.Quantity)
+}
@@ -0,0 +1,81 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strconv"
+	"strings"
+)
+
+// RedisCache is priced per cache node.  Standard caches run a primary and a replica, and Premium caches run a primary
+// and replicas_per_primary replicas for each of their shards.
+type RedisCache struct {
+	Location      string
+	Tier          string
+	Sku           string
+	Nodes         float64
+	PricingScheme PricingScheme
+}
+
+func newRedisCache(values map[string]interface{}, priceType PricingScheme) *RedisCache {
+	capacity, _ := getFloat(values, "capacity")
+	r := &RedisCache{
+		Location:      getString(values, "location"),
+		Tier:          getString(values, "sku_name"),
+		Sku:           fmt.Sprintf("%s%g", getString(values, "family"), capacity),
+		Nodes:         1,
+		PricingScheme: priceType,
+	}
+	switch r.Tier {
+	case "Standard":
+		r.Nodes = 2
+	case "Premium":
+		shards, ok := getFloat(values, "shard_count")
+		if !ok || shards < 1 {
+			shards = 1
+		}
+		replicas, ok := getFloat(values, "replicas_per_primary")
+		if !ok || replicas < 1 {
+			replicas = 1
+		}
+		r.Nodes = shards * (1 + replicas)
+	}
+	return r
+}
+
+func (r *RedisCache) pricers() []types.Priceable {
+	return []types.Priceable{&meter{
+		Filter: fmt.Sprintf("serviceName eq 'Redis Cache' and armRegionName eq '%s' and (contains(productName,'%s') eq true) and skuName eq '%s' and (contains(meterName,'Cache') eq true)",
+			r.Location, r.Tier, r.Sku),
+		Quantity: types.FixedRange(r.Nodes),
+		Period:   perHour,
+		//Azure only offers reservations for Premium and Enterprise caches, the others fall back to pay-as-you-go
+		Reservable:    true,
+		PricingScheme: r.PricingScheme,
+	}}
+}
+
+// redisEnterprisePricers prices an azurerm_redis_enterprise_cluster from its sku_name, a tier and size followed by the
+// capacity in units of that size, e.g. Enterprise_E10-2 or EnterpriseFlash_F300-3
+func redisEnterprisePricers(values map[string]interface{}, priceType PricingScheme) []types.Priceable {
+	parts := strings.SplitN(getString(values, "sku_name"), "_", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	flash := parts[0] == "EnterpriseFlash"
+	size := strings.SplitN(parts[1], "-", 2)
+	capacity := 1.0
+	if len(size) == 2 {
+		if units, err := strconv.ParseFloat(size[1], 64); err == nil && units > 0 {
+			capacity = units
+		}
+	}
+	return []types.Priceable{&meter{
+		Filter: fmt.Sprintf("serviceName eq 'Redis Cache' and armRegionName eq '%s' and (contains(productName,'Enterprise') eq true) and (contains(productName,'Flash') eq %t) and skuName eq '%s' and (contains(meterName,'Cache') eq true)",
+			getString(values, "location"), flash, size[0]),
+		Quantity:      types.FixedRange(capacity),
+		Period:        perHour,
+		Reservable:    true,
+		PricingScheme: priceType,
+	}}
+}
@@ -0,0 +1,55 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestRedisCacheNodes(t *testing.T) {
+	cases := []struct {
+		values map[string]interface{}
+		sku    string
+		nodes  float64
+	}{
+		{map[string]interface{}{"sku_name": "Basic", "family": "C", "capacity": 0.0}, "C0", 1},
+		{map[string]interface{}{"sku_name": "Standard", "family": "C", "capacity": 1.0}, "C1", 2},
+		{map[string]interface{}{"sku_name": "Premium", "family": "P", "capacity": 2.0}, "P2", 2},
+		{map[string]interface{}{"sku_name": "Premium", "family": "P", "capacity": 1.0, "shard_count": 3.0}, "P1", 6},
+		{map[string]interface{}{"sku_name": "Premium", "family": "P", "capacity": 4.0, "shard_count": 2.0, "replicas_per_primary": 3.0}, "P4", 8},
+	}
+	for _, c := range cases {
+		cache := newRedisCache(c.values, Consumption)
+		assert.Equal(t, c.sku, cache.Sku, "%v", c.values)
+		assert.Equal(t, c.nodes, cache.Nodes, "%v", c.values)
+		m := cache.pricers()[0].(*meter)
+		assert.Equal(t, types.FixedRange(c.nodes), m.Quantity, "%v", c.values)
+		assert.Contains(t, m.Filter, "skuName eq '"+c.sku+"'")
+	}
+}
+
+func TestRedisEnterpriseSku(t *testing.T) {
+	cases := []struct {
+		skuName  string
+		size     string
+		flash    string
+		capacity float64
+	}{
+		{"Enterprise_E10-2", "E10", "eq false", 2},
+		{"Enterprise_E100-8", "E100", "eq false", 8},
+		{"EnterpriseFlash_F300-3", "F300", "eq true", 3},
+		{"Enterprise_E20", "E20", "eq false", 1},
+		{"Enterprise_E20-x", "E20", "eq false", 1},
+	}
+	for _, c := range cases {
+		pricers := redisEnterprisePricers(map[string]interface{}{"sku_name": c.skuName, "location": "eastus"}, Consumption)
+		if !assert.Len(t, pricers, 1, c.skuName) {
+			continue
+		}
+		m := pricers[0].(*meter)
+		assert.Equal(t, types.FixedRange(c.capacity), m.Quantity, c.skuName)
+		assert.Contains(t, m.Filter, "skuName eq '"+c.size+"'", c.skuName)
+		assert.Contains(t, m.Filter, "(contains(productName,'Flash') "+c.flash+")", c.skuName)
+	}
+	assert.Empty(t, redisEnterprisePricers(map[string]interface{}{"sku_name": "E10"}, Consumption))
+}
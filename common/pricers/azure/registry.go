@@ -0,0 +1,46 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+)
+
+// Storage included in the daily price of a registry, beyond which what it stores is billed per GB
+var containerRegistryIncludedStorageGb = map[string]float64{
+	"Basic":    10,
+	"Standard": 100,
+	"Premium":  500,
+}
+
+// containerRegistryPricers prices a registry's daily fee by sku, one Premium registry unit for each region it's
+// geo-replicated to, and the storage it uses beyond what its sku includes
+func containerRegistryPricers(values map[string]interface{}, usage types.Usage) []types.Priceable {
+	location := getString(values, "location")
+	sku := getString(values, "sku")
+	if sku == "" {
+		sku = "Classic"
+	}
+	registryUnit := func(location, sku string) *meter {
+		return &meter{
+			Filter:   fmt.Sprintf("serviceName eq 'Container Registry' and armRegionName eq '%s' and skuName eq '%s' and (contains(meterName,'Registry Unit') eq true)", location, sku),
+			Quantity: types.FixedRange(1),
+			Period:   perDay,
+		}
+	}
+	pricers := []types.Priceable{registryUnit(location, sku)}
+	//georeplication_locations was replaced by georeplications blocks in v2.x of the provider
+	replicas, _ := values["georeplication_locations"].([]interface{})
+	for _, replica := range replicas {
+		if region, ok := replica.(string); ok {
+			pricers = append(pricers, registryUnit(region, "Premium"))
+		}
+	}
+	for _, replica := range getBlocks(values, "georeplications") {
+		pricers = append(pricers, registryUnit(getString(replica, "location"), "Premium"))
+	}
+	if stored, ok := usage.Get("storage_gb"); ok && stored > containerRegistryIncludedStorageGb[sku] {
+		pricers = append(pricers, monthlyMeter(fmt.Sprintf(
+			"serviceName eq 'Container Registry' and armRegionName eq '%s' and (contains(meterName,'Data Stored') eq true)", location), stored-containerRegistryIncludedStorageGb[sku]))
+	}
+	return pricers
+}
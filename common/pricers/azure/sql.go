@@ -0,0 +1,267 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strconv"
+	"strings"
+)
+
+// The default sku_name of an azurerm_mssql_database when the server picks one
+const DEFAULT_SQL_DATABASE_SKU = "GP_Gen5_2"
+
+// The service objective a legacy azurerm_sql_database runs at by default for its edition, when it doesn't request one
+var sqlEditionDefaultSkus = map[string]string{
+	"Basic":            "Basic",
+	"Standard":         "S0",
+	"Premium":          "P1",
+	"GeneralPurpose":   "GP_Gen5_2",
+	"BusinessCritical": "BC_Gen5_2",
+	"Hyperscale":       "HS_Gen5_2",
+}
+
+// vCore sku_name prefixes and the service tiers they appear as in the pricing API's product names
+var sqlVcoreTiers = map[string]string{
+	"GP": "General Purpose",
+	"BC": "Business Critical",
+	"HS": "Hyperscale",
+}
+
+// Hardware families in a vCore sku_name, as they appear at the end of the compute product names
+var sqlFamilyProducts = map[string]string{
+	"Gen5":   "Gen5",
+	"Fsv2":   "FSv2 Series",
+	"DC":     "DC-Series",
+	"M":      "M Series",
+	"PRMS":   "Premium Series",
+	"MOPRMS": "Premium Series Memory Optimized",
+}
+
+// Storage included in the price of a DTU database, beyond which its max_size_gb is billed as extra storage
+var sqlDtuIncludedStorageGb = map[string]float64{
+	"Basic":    2,
+	"Standard": 250,
+	"Premium":  500,
+}
+
+/*
+A sqlSku is a decoded sku_name, which is either a vCore model name made up of a tier, an optional "S" for serverless,
+a hardware family and a number of vCores, e.g. GP_S_Gen5_2, or a DTU model name such as Basic, S3 or PremiumPool.
+Elastic pools and managed instances leave the capacity off the name.
+*/
+type sqlSku struct {
+	Tier         string
+	IsVcore      bool
+	IsServerless bool
+	Family       string
+	Capacity     float64
+	//The name of a DTU sku as it appears in the pricing API, e.g. S3
+	DtuName string
+}
+
+func parseSqlSku(name string) sqlSku {
+	parts := strings.Split(name, "_")
+	if tier, ok := sqlVcoreTiers[parts[0]]; ok {
+		sku := sqlSku{Tier: tier, IsVcore: true}
+		parts = parts[1:]
+		if len(parts) > 0 && parts[0] == "S" {
+			sku.IsServerless, parts = true, parts[1:]
+		}
+		if len(parts) > 0 {
+			sku.Family, parts = parts[0], parts[1:]
+			if product, ok := sqlFamilyProducts[sku.Family]; ok {
+				sku.Family = product
+			}
+		}
+		if len(parts) > 0 {
+			sku.Capacity, _ = strconv.ParseFloat(parts[0], 64)
+		}
+		return sku
+	}
+	switch {
+	case strings.HasPrefix(name, "Basic"):
+		return sqlSku{Tier: "Basic", DtuName: "B"}
+	case strings.HasPrefix(name, "Standard"), strings.HasPrefix(name, "S"):
+		return sqlSku{Tier: "Standard", DtuName: name}
+	case strings.HasPrefix(name, "Premium"), strings.HasPrefix(name, "P"):
+		return sqlSku{Tier: "Premium", DtuName: name}
+	}
+	return sqlSku{}
+}
+
+// sqlVcore is the compute, licence and storage of a vCore database, elastic pool or managed instance
+type sqlVcore struct {
+	Location        string
+	Sku             sqlSku
+	ManagedInstance bool
+	StorageGb       float64
+	ZoneRedundant   bool
+	//Azure Hybrid Benefit, license_type BasePrice, brings an existing SQL Server licence instead of paying for one
+	HybridBenefit bool
+}
+
+func (v *sqlVcore) filter() string {
+	return fmt.Sprintf("serviceName eq 'SQL Database' and armRegionName eq '%s' and (contains(productName,'%s') eq true) and (contains(productName,'Managed Instance') eq %t)",
+		v.Location, v.Sku.Tier, v.ManagedInstance)
+}
+
+// zoneRedundant is whether zone redundancy is billed, Business Critical replicas are spread across zones for free
+func (v *sqlVcore) zoneRedundant() bool {
+	return v.ZoneRedundant && v.Sku.Tier != "Business Critical"
+}
+
+// pricers prices the vCores as a quantity of hours per month, which lets serverless compute bill for part of a month
+func (v *sqlVcore) pricers(vcoreHours types.Range) []types.Priceable {
+	pricers := []types.Priceable{&meter{
+		Filter: v.filter() + fmt.Sprintf(" and (contains(productName,'Compute %s') eq true) and (contains(productName,'Serverless') eq %t) and (contains(skuName,'Zone Redundancy') eq %t)",
+			v.Sku.Family, v.Sku.IsServerless, v.zoneRedundant()),
+		Quantity: vcoreHours,
+		Period:   perMonth,
+	}}
+	//Serverless compute includes the licence
+	if !v.HybridBenefit && !v.Sku.IsServerless {
+		pricers = append(pricers, &meter{
+			Filter:   v.filter() + " and (contains(productName,'SQL License') eq true)",
+			Quantity: vcoreHours,
+			Period:   perMonth,
+		})
+	}
+	if v.StorageGb > 0 {
+		pricers = append(pricers, monthlyMeter(v.filter()+fmt.Sprintf(" and (contains(productName,'Storage') eq true) and (contains(meterName,'Data Stored') eq true) and (contains(meterName,'Zone Redundancy') eq %t)",
+			v.zoneRedundant()), v.StorageGb))
+	}
+	return pricers
+}
+
+// mssqlDatabasePricers prices a database that isn't in an elastic pool from its sku, or nothing for skus such as data
+// warehouses that aren't priced yet
+func mssqlDatabasePricers(location string, values map[string]interface{}, skuName string, maxSizeGb float64, usage types.Usage, monthHours float64) []types.Priceable {
+	if skuName == "" {
+		skuName = DEFAULT_SQL_DATABASE_SKU
+	}
+	sku := parseSqlSku(skuName)
+	if sku.Tier == "" {
+		return nil
+	}
+	if !sku.IsVcore {
+		return sqlDtuPricers(location, sku, maxSizeGb)
+	}
+
+	db := &sqlVcore{
+		Location:      location,
+		Sku:           sku,
+		StorageGb:     maxSizeGb,
+		ZoneRedundant: getBool(values, "zone_redundant"),
+		HybridBenefit: getString(values, "license_type") == "BasePrice",
+	}
+	var pricers []types.Priceable
+	if sku.Tier == "Hyperscale" {
+		//Hyperscale storage grows with the data rather than being provisioned, and each read replica is billed as compute
+		db.StorageGb = 0
+		if stored := usageMeter(usage, "storage_gb", db.filter()+" and (contains(productName,'Storage') eq true) and (contains(meterName,'Data Stored') eq true)"); stored != nil {
+			pricers = append(pricers, stored)
+		} else {
+			pricers = append(pricers, note("no storage_gb usage given for this Hyperscale database, its storage isn't priced"))
+		}
+		replicas, _ := getFloat(values, "read_replica_count")
+		sku.Capacity *= 1 + replicas
+	}
+	return append(db.pricers(sqlVcoreHours(values, sku, usage, monthHours)), pricers...)
+}
+
+// sqlVcoreHours is the vCore hours a database is billed for in a month.  Serverless databases are billed for the vCores
+// they use, at least min_capacity while they're running, and nothing while they're paused.  How long that is comes
+// from the active_hours usage, otherwise they could be paused the whole month when auto-pause is on.
+func sqlVcoreHours(values map[string]interface{}, sku sqlSku, usage types.Usage, monthHours float64) types.Range {
+	if !sku.IsServerless {
+		return types.FixedRange(sku.Capacity * monthHours)
+	}
+	minCapacity, ok := getFloat(values, "min_capacity")
+	if !ok || minCapacity == 0 {
+		minCapacity = sku.Capacity
+	}
+	hours := types.FixedRange(monthHours)
+	if active, ok := usage.Get("active_hours"); ok {
+		hours = types.FixedRange(active)
+	} else if delay, _ := getFloat(values, "auto_pause_delay_in_minutes"); delay != -1 {
+		hours.Min = 0
+	}
+	return types.Range{Min: minCapacity * hours.Min, Expected: minCapacity * hours.Expected, Max: sku.Capacity * hours.Max}
+}
+
+// sqlDtuPricers prices a DTU database, which has a daily price that includes some storage
+func sqlDtuPricers(location string, sku sqlSku, maxSizeGb float64) []types.Priceable {
+	product := fmt.Sprintf("serviceName eq 'SQL Database' and armRegionName eq '%s' and productName eq 'SQL Database Single %s'", location, sku.Tier)
+	pricers := []types.Priceable{&meter{
+		Filter:   product + fmt.Sprintf(" and skuName eq '%s' and (contains(meterName,'DTU') eq true)", sku.DtuName),
+		Quantity: types.FixedRange(1),
+		Period:   perDay,
+	}}
+	if extra := maxSizeGb - sqlDtuIncludedStorageGb[sku.Tier]; sku.Tier != "Basic" && extra > 0 {
+		pricers = append(pricers, monthlyMeter(product+" and (contains(meterName,'Data Stored') eq true)", extra))
+	}
+	return pricers
+}
+
+// mssqlElasticPoolPricers prices a pool from its sku block, a number of vCores or eDTUs shared by its databases
+func mssqlElasticPoolPricers(values map[string]interface{}, monthHours float64) []types.Priceable {
+	block := getBlock(values, "sku")
+	location := getString(values, "location")
+	capacity, _ := getFloat(block, "capacity")
+	maxSizeGb, _ := getFloat(values, "max_size_gb")
+	sku := parseSqlSku(getString(block, "name"))
+	if !sku.IsVcore {
+		return sqlElasticPoolDtuPricers(location, sku.Tier, capacity)
+	}
+	if family := getString(block, "family"); family != "" {
+		sku.Family = family
+		if product, ok := sqlFamilyProducts[family]; ok {
+			sku.Family = product
+		}
+	}
+	pool := &sqlVcore{
+		Location:      location,
+		Sku:           sku,
+		StorageGb:     maxSizeGb,
+		ZoneRedundant: getBool(values, "zone_redundant"),
+		HybridBenefit: getString(values, "license_type") == "BasePrice",
+	}
+	return pool.pricers(types.FixedRange(capacity * monthHours))
+}
+
+// sqlElasticPoolDtuPricers prices a DTU pool by its size, matching the meter name exactly since a 200 eDTU pool's name
+// is part of the 1200 eDTU pool's
+func sqlElasticPoolDtuPricers(location, tier string, edtus float64) []types.Priceable {
+	return []types.Priceable{&meter{
+		Filter: fmt.Sprintf("serviceName eq 'SQL Database' and armRegionName eq '%s' and productName eq 'SQL Database Elastic Pool - %s' and meterName eq '%g eDTU'",
+			location, tier, edtus),
+		Quantity: types.FixedRange(1),
+		Period:   perDay,
+	}}
+}
+
+// mssqlManagedInstancePricers prices a managed instance from its sku_name, e.g. GP_Gen5, and its vcores
+func mssqlManagedInstancePricers(values map[string]interface{}, monthHours float64) []types.Priceable {
+	vcores, _ := getFloat(values, "vcores")
+	storage, _ := getFloat(values, "storage_size_in_gb")
+	instance := &sqlVcore{
+		Location:        getString(values, "location"),
+		Sku:             parseSqlSku(getString(values, "sku_name")),
+		ManagedInstance: true,
+		StorageGb:       storage,
+		ZoneRedundant:   getBool(values, "zone_redundant_enabled"),
+		HybridBenefit:   getString(values, "license_type") == "BasePrice",
+	}
+	return instance.pricers(types.FixedRange(vcores * monthHours))
+}
+
+// sqlDatabaseMaxSizeGb reads the maximum size of a legacy azurerm_sql_database, which is a string of GB or of bytes
+func sqlDatabaseMaxSizeGb(values map[string]interface{}) float64 {
+	if gb, err := strconv.ParseFloat(getString(values, "max_size_gb"), 64); err == nil {
+		return gb
+	}
+	if bytes, err := strconv.ParseFloat(getString(values, "max_size_bytes"), 64); err == nil {
+		return bytes / (1024 * 1024 * 1024)
+	}
+	return 0
+}
@@ -0,0 +1,135 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+	"testing"
+)
+
+func TestParseSqlSku(t *testing.T) {
+	cases := []struct {
+		name string
+		sku  sqlSku
+	}{
+		{"GP_Gen5_2", sqlSku{Tier: "General Purpose", IsVcore: true, Family: "Gen5", Capacity: 2}},
+		{"GP_S_Gen5_4", sqlSku{Tier: "General Purpose", IsVcore: true, IsServerless: true, Family: "Gen5", Capacity: 4}},
+		{"BC_M_8", sqlSku{Tier: "Business Critical", IsVcore: true, Family: "M Series", Capacity: 8}},
+		{"HS_PRMS_4", sqlSku{Tier: "Hyperscale", IsVcore: true, Family: "Premium Series", Capacity: 4}},
+		{"GP_Fsv2_72", sqlSku{Tier: "General Purpose", IsVcore: true, Family: "FSv2 Series", Capacity: 72}},
+		// elastic pools and managed instances leave the capacity off
+		{"BC_Gen5", sqlSku{Tier: "Business Critical", IsVcore: true, Family: "Gen5"}},
+		{"Basic", sqlSku{Tier: "Basic", DtuName: "B"}},
+		{"S3", sqlSku{Tier: "Standard", DtuName: "S3"}},
+		{"P11", sqlSku{Tier: "Premium", DtuName: "P11"}},
+		{"StandardPool", sqlSku{Tier: "Standard", DtuName: "StandardPool"}},
+		{"PremiumPool", sqlSku{Tier: "Premium", DtuName: "PremiumPool"}},
+		{"DW100c", sqlSku{}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.sku, parseSqlSku(c.name), c.name)
+	}
+
+	editionTiers := map[string]string{
+		"Basic":            "Basic",
+		"Standard":         "Standard",
+		"Premium":          "Premium",
+		"GeneralPurpose":   "General Purpose",
+		"BusinessCritical": "Business Critical",
+		"Hyperscale":       "Hyperscale",
+	}
+	for edition, tier := range editionTiers {
+		assert.Equal(t, tier, parseSqlSku(sqlEditionDefaultSkus[edition]).Tier, edition)
+	}
+}
+
+func TestSqlVcoreHours(t *testing.T) {
+	cases := []struct {
+		name   string
+		sku    string
+		values map[string]interface{}
+		usage  types.Usage
+		hours  types.Range
+	}{
+		{"provisioned", "GP_Gen5_4", map[string]interface{}{}, types.Usage{}, types.FixedRange(4 * 730)},
+		{"serverless with auto-pause", "GP_S_Gen5_4", map[string]interface{}{"min_capacity": 0.5, "auto_pause_delay_in_minutes": 60.0}, types.Usage{},
+			types.Range{Min: 0, Expected: 0.5 * 730, Max: 4 * 730}},
+		{"serverless without auto-pause", "GP_S_Gen5_4", map[string]interface{}{"min_capacity": 0.5, "auto_pause_delay_in_minutes": -1.0}, types.Usage{},
+			types.Range{Min: 0.5 * 730, Expected: 0.5 * 730, Max: 4 * 730}},
+		{"serverless with active hours", "GP_S_Gen5_4", map[string]interface{}{"min_capacity": 0.5, "auto_pause_delay_in_minutes": 60.0}, types.Usage{"active_hours": 200},
+			types.Range{Min: 0.5 * 200, Expected: 0.5 * 200, Max: 4 * 200}},
+		{"serverless without min_capacity", "GP_S_Gen5_2", map[string]interface{}{"auto_pause_delay_in_minutes": -1.0}, types.Usage{},
+			types.FixedRange(2 * 730)},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.hours, sqlVcoreHours(c.values, parseSqlSku(c.sku), c.usage, 730), c.name)
+	}
+}
+
+func TestSqlDtuExtraStorage(t *testing.T) {
+	cases := []struct {
+		sku       string
+		maxSizeGb float64
+		extraGb   float64
+	}{
+		{"Basic", 2, 0},
+		{"S3", 250, 0},
+		{"S3", 500, 250},
+		{"P1", 500, 0},
+		{"P1", 1024, 524},
+	}
+	for _, c := range cases {
+		pricers := mssqlDatabasePricers("eastus", map[string]interface{}{}, c.sku, c.maxSizeGb, types.Usage{}, 730)
+		m := pricers[0].(*meter)
+		assert.Equal(t, perDay, m.Period, c.sku)
+		assert.Contains(t, m.Filter, "skuName eq '"+parseSqlSku(c.sku).DtuName+"'", c.sku)
+		if c.extraGb == 0 {
+			assert.Len(t, pricers, 1, "%s %g", c.sku, c.maxSizeGb)
+		} else if assert.Len(t, pricers, 2, "%s %g", c.sku, c.maxSizeGb) {
+			assert.Equal(t, types.FixedRange(c.extraGb), pricers[1].(*meter).Quantity, "%s %g", c.sku, c.maxSizeGb)
+		}
+	}
+}
+
+func TestSqlVcoreDatabaseMeters(t *testing.T) {
+	cases := []struct {
+		name    string
+		sku     string
+		values  map[string]interface{}
+		usage   types.Usage
+		compute types.Range
+		license bool
+		meters  int
+	}{
+		{"general purpose", "GP_Gen5_2", map[string]interface{}{}, types.Usage{}, types.FixedRange(2 * 730), true, 3},
+		{"hybrid benefit", "GP_Gen5_2", map[string]interface{}{"license_type": "BasePrice"}, types.Usage{}, types.FixedRange(2 * 730), false, 2},
+		{"serverless includes its licence", "GP_S_Gen5_2", map[string]interface{}{"auto_pause_delay_in_minutes": -1.0}, types.Usage{}, types.FixedRange(2 * 730), false, 2},
+		// each read replica is billed as compute, and storage comes from usage rather than max_size_gb
+		{"hyperscale with replicas", "HS_Gen5_2", map[string]interface{}{"read_replica_count": 2.0}, types.Usage{"storage_gb": 100}, types.FixedRange(6 * 730), true, 3},
+		{"hyperscale without storage usage", "HS_Gen5_2", map[string]interface{}{}, types.Usage{}, types.FixedRange(2 * 730), true, 3},
+	}
+	for _, c := range cases {
+		pricers := mssqlDatabasePricers("eastus", c.values, c.sku, 32, c.usage, 730)
+		if !assert.Len(t, pricers, c.meters, c.name) {
+			continue
+		}
+		assert.Equal(t, c.compute, pricers[0].(*meter).Quantity, c.name)
+		license := false
+		for _, p := range pricers {
+			if m, ok := p.(*meter); ok && strings.Contains(m.Filter, "(contains(productName,'SQL License') eq true)") {
+				license = true
+			}
+		}
+		assert.Equal(t, c.license, license, c.name)
+	}
+	assert.Empty(t, mssqlDatabasePricers("eastus", map[string]interface{}{}, "DW100c", 0, types.Usage{}, 730))
+}
+
+func TestSqlElasticPoolDtuMeter(t *testing.T) {
+	pricers := sqlElasticPoolDtuPricers("eastus", "Standard", 200)
+	if assert.Len(t, pricers, 1) {
+		m := pricers[0].(*meter)
+		assert.Contains(t, m.Filter, "meterName eq '200 eDTU'")
+		assert.NotContains(t, m.Filter, "contains(meterName")
+	}
+}
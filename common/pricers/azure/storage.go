@@ -0,0 +1,128 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"strings"
+)
+
+// Product names in the pricing API for blob storage by account_kind.  Premium accounts are looked up separately.
+var storageKindToBlobProductMap = map[string]string{
+	"StorageV2":   "General Block Blob v2",
+	"Storage":     "General Block Blob",
+	"BlobStorage": "Blob Storage",
+}
+
+// The pricing API spells the read access redundancy options with a hyphen
+var storageReplicationSkuMap = map[string]string{
+	"LRS":    "LRS",
+	"ZRS":    "ZRS",
+	"GRS":    "GRS",
+	"RAGRS":  "RA-GRS",
+	"GZRS":   "GZRS",
+	"RAGZRS": "RA-GZRS",
+}
+
+type StorageAccount struct {
+	Location        string
+	AccountKind     string
+	AccountTier     string
+	ReplicationType string
+	AccessTier      string
+}
+
+func newStorageAccount(values map[string]interface{}) *StorageAccount {
+	s := &StorageAccount{
+		Location:        getString(values, "location"),
+		AccountKind:     getString(values, "account_kind"),
+		AccountTier:     getString(values, "account_tier"),
+		ReplicationType: getString(values, "account_replication_type"),
+		AccessTier:      getString(values, "access_tier"),
+	}
+	if s.AccountKind == "" {
+		s.AccountKind = "StorageV2"
+	}
+	if s.AccessTier == "" {
+		s.AccessTier = "Hot"
+	}
+	return s
+}
+
+// pricers prices the blob capacity, operations and geo-replication of a storage account from its usage.  A storage
+// account costs nothing until it is used, so without usage it is a zero cost line item explaining why.
+func (s *StorageAccount) pricers(usage types.Usage) []types.Priceable {
+	if s.AccountKind == "FileStorage" {
+		//Premium file shares are billed on their provisioned quota, see storageSharePricers
+		return []types.Priceable{note("FileStorage accounts are billed through the quota of their azurerm_storage_share resources")}
+	}
+	blob := s.blobFilter()
+	//Iterative operations belong to hierarchical namespace (Data Lake) accounts
+	notIterative := "(contains(meterName,'Iterative') eq false)"
+	pricers := appendMeters(nil,
+		tieredMeter(usageMeter(usage, "storage_gb", blob+" and (contains(meterName,'Data Stored') eq true)")),
+		usageMeter(usage, "write_operations", blob+" and (contains(meterName,'Write Operations') eq true) and "+notIterative),
+		usageMeter(usage, "read_operations", blob+" and (contains(meterName,'Read Operations') eq true) and "+notIterative),
+		usageMeter(usage, "other_operations", blob+" and (contains(meterName,'Other Operations') eq true)"),
+	)
+	if s.isGeoReplicated() {
+		pricers = appendMeters(pricers, usageMeter(usage, "geo_replication_gb", fmt.Sprintf(
+			"serviceName eq 'Storage' and armRegionName eq '%s' and (contains(meterName,'Geo-Replication') eq true)", s.Location)))
+	}
+	if len(pricers) == 0 {
+		return []types.Priceable{note("no usage given for this storage account, add storage_gb and operations to a usage profile to price it")}
+	}
+	return pricers
+}
+
+func (s *StorageAccount) blobFilter() string {
+	redundancy := storageReplicationSkuMap[s.ReplicationType]
+	product := storageKindToBlobProductMap[s.AccountKind]
+	sku := s.AccessTier + " " + redundancy
+	switch {
+	case s.AccountTier == "Premium" && s.AccountKind == "BlockBlobStorage":
+		product, sku = "Premium Block Blob", "Premium "+redundancy
+	case s.AccountTier == "Premium":
+		product, sku = "Premium Page Blob", "Premium "+redundancy
+	case s.AccountKind == "Storage":
+		//General purpose v1 accounts don't have access tiers
+		sku = "Standard " + redundancy
+	}
+	return fmt.Sprintf("serviceName eq 'Storage' and armRegionName eq '%s' and productName eq '%s' and skuName eq '%s'", s.Location, product, sku)
+}
+
+func (s *StorageAccount) isGeoReplicated() bool {
+	return strings.Contains(s.ReplicationType, "GRS") || strings.Contains(s.ReplicationType, "GZRS")
+}
+
+// storageSharePricers prices an azurerm_storage_share in the storage account it belongs to.  Premium shares are billed
+// on their provisioned quota, standard shares on the capacity they use, which can be anything up to the quota when
+// the usage profile doesn't say.
+func storageSharePricers(account *StorageAccount, values map[string]interface{}, usage types.Usage) []types.Priceable {
+	quota, _ := getFloat(values, "quota")
+	redundancy := storageReplicationSkuMap[account.ReplicationType]
+	if account.AccountKind == "FileStorage" {
+		return []types.Priceable{monthlyMeter(fmt.Sprintf(
+			"serviceName eq 'Storage' and armRegionName eq '%s' and productName eq 'Premium Files' and skuName eq 'Premium %s' and (contains(meterName,'Provisioned') eq true)",
+			account.Location, redundancy), quota)}
+	}
+
+	product := "Files v2"
+	if account.AccountKind == "Storage" {
+		product = "Files"
+	}
+	filter := fmt.Sprintf("serviceName eq 'Storage' and armRegionName eq '%s' and productName eq '%s' and (contains(skuName,'%s') eq true) and (contains(meterName,'Data Stored') eq true)",
+		account.Location, product, redundancy)
+	switch tier := getString(values, "access_tier"); tier {
+	case "Hot", "Cool":
+		filter += fmt.Sprintf(" and (contains(skuName,'%s') eq true)", tier)
+	default:
+		filter += " and (contains(skuName,'Hot') eq false) and (contains(skuName,'Cool') eq false)"
+	}
+	if stored := usageMeter(usage, "storage_gb", filter); stored != nil {
+		return []types.Priceable{stored}
+	}
+	return []types.Priceable{
+		&meter{Filter: filter, Quantity: types.Range{Max: quota}, Period: perMonth},
+		note("no usage given for this share, priced from empty up to its quota"),
+	}
+}
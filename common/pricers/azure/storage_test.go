@@ -0,0 +1,98 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+	"testing"
+)
+
+func TestStorageAccountBlobFilter(t *testing.T) {
+	cases := []struct {
+		values  map[string]interface{}
+		product string
+		sku     string
+	}{
+		{map[string]interface{}{"account_tier": "Standard", "account_replication_type": "LRS"}, "General Block Blob v2", "Hot LRS"},
+		{map[string]interface{}{"account_kind": "StorageV2", "account_tier": "Standard", "account_replication_type": "RAGRS", "access_tier": "Cool"}, "General Block Blob v2", "Cool RA-GRS"},
+		{map[string]interface{}{"account_kind": "StorageV2", "account_tier": "Standard", "account_replication_type": "RAGZRS"}, "General Block Blob v2", "Hot RA-GZRS"},
+		{map[string]interface{}{"account_kind": "BlobStorage", "account_tier": "Standard", "account_replication_type": "GRS", "access_tier": "Cool"}, "Blob Storage", "Cool GRS"},
+		// general purpose v1 accounts have no access tier, whatever the plan says
+		{map[string]interface{}{"account_kind": "Storage", "account_tier": "Standard", "account_replication_type": "ZRS", "access_tier": "Cool"}, "General Block Blob", "Standard ZRS"},
+		{map[string]interface{}{"account_kind": "BlockBlobStorage", "account_tier": "Premium", "account_replication_type": "ZRS"}, "Premium Block Blob", "Premium ZRS"},
+		{map[string]interface{}{"account_kind": "StorageV2", "account_tier": "Premium", "account_replication_type": "LRS"}, "Premium Page Blob", "Premium LRS"},
+	}
+	for _, c := range cases {
+		c.values["location"] = "eastus"
+		filter := newStorageAccount(c.values).blobFilter()
+		assert.Contains(t, filter, "productName eq '"+c.product+"'", "%v", c.values)
+		assert.Contains(t, filter, "skuName eq '"+c.sku+"'", "%v", c.values)
+		assert.Contains(t, filter, "armRegionName eq 'eastus'", "%v", c.values)
+	}
+}
+
+func TestStorageAccountGeoReplication(t *testing.T) {
+	cases := []struct {
+		replication string
+		meters      int
+	}{
+		{"LRS", 1},
+		{"ZRS", 1},
+		{"GRS", 2},
+		{"RAGRS", 2},
+		{"GZRS", 2},
+		{"RAGZRS", 2},
+	}
+	for _, c := range cases {
+		account := newStorageAccount(map[string]interface{}{"account_tier": "Standard", "account_replication_type": c.replication, "location": "eastus"})
+		pricers := account.pricers(types.Usage{"storage_gb": 100, "geo_replication_gb": 10})
+		assert.Len(t, pricers, c.meters, c.replication)
+	}
+
+	pricers := newStorageAccount(map[string]interface{}{"account_kind": "FileStorage", "account_tier": "Premium"}).pricers(types.Usage{"storage_gb": 100})
+	if assert.Len(t, pricers, 1) {
+		assert.IsType(t, note(""), pricers[0])
+	}
+}
+
+func TestStorageShareFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		account  map[string]interface{}
+		share    map[string]interface{}
+		contains []string
+	}{
+		{"premium share on its quota", map[string]interface{}{"account_kind": "FileStorage", "account_tier": "Premium", "account_replication_type": "ZRS"},
+			map[string]interface{}{"quota": 100.0},
+			[]string{"productName eq 'Premium Files'", "skuName eq 'Premium ZRS'", "(contains(meterName,'Provisioned') eq true)"}},
+		{"hot share", map[string]interface{}{"account_tier": "Standard", "account_replication_type": "GRS"},
+			map[string]interface{}{"quota": 100.0, "access_tier": "Hot"},
+			[]string{"productName eq 'Files v2'", "(contains(skuName,'GRS') eq true)", "(contains(skuName,'Hot') eq true)"}},
+		{"cool share", map[string]interface{}{"account_tier": "Standard", "account_replication_type": "LRS"},
+			map[string]interface{}{"quota": 100.0, "access_tier": "Cool"},
+			[]string{"(contains(skuName,'Cool') eq true)"}},
+		{"transaction optimized share", map[string]interface{}{"account_tier": "Standard", "account_replication_type": "LRS"},
+			map[string]interface{}{"quota": 100.0, "access_tier": "TransactionOptimized"},
+			[]string{"(contains(skuName,'Hot') eq false) and (contains(skuName,'Cool') eq false)"}},
+		{"general purpose v1 share", map[string]interface{}{"account_kind": "Storage", "account_tier": "Standard", "account_replication_type": "LRS"},
+			map[string]interface{}{"quota": 100.0},
+			[]string{"productName eq 'Files'"}},
+	}
+	for _, c := range cases {
+		c.account["location"] = "eastus"
+		pricers := storageSharePricers(newStorageAccount(c.account), c.share, types.Usage{"storage_gb": 50})
+		if !assert.Len(t, pricers, 1, c.name) {
+			continue
+		}
+		m := pricers[0].(*meter)
+		for _, s := range c.contains {
+			assert.Contains(t, m.Filter, s, c.name)
+		}
+	}
+
+	// without usage a standard share could use anything up to its quota
+	account := newStorageAccount(map[string]interface{}{"account_tier": "Standard", "account_replication_type": "LRS"})
+	pricers := storageSharePricers(account, map[string]interface{}{"quota": 100.0}, types.Usage{})
+	if assert.Len(t, pricers, 2) {
+		assert.Equal(t, types.Range{Max: 100}, pricers[0].(*meter).Quantity)
+	}
+}
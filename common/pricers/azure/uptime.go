@@ -0,0 +1,73 @@
+package azure
+
+import "github.com/zparnold/terraform-cost-estimator/common/types"
+
+// A shutdown schedule only stops a VM, so we assume it is started again at the beginning of each working day
+const DEFAULT_WORKDAY_START = 9.0
+
+// scheduledPricer is implemented by pricers that are only billed while they are running, such as virtual machines
+type scheduledPricer interface {
+	setUptime(uptime types.Range)
+}
+
+// resolveUptime works out the share of the month a resource runs for, preferring a user supplied uptime profile that
+// matches its address, then its tags, then its type, and otherwise any azurerm_dev_test_global_vm_shutdown_schedule
+// attached to it
+func resolveUptime(graph *planGraph, res *planResource, profiles types.UptimeProfiles, monthHours float64) (types.Range, bool) {
+	if profile, ok := matchUptimeProfile(res, profiles); ok {
+		fraction, err := profile.Fraction(monthHours)
+		if err == nil {
+			return types.FixedRange(fraction), true
+		}
+	}
+	for _, schedule := range graph.referencing(res, "azurerm_dev_test_global_vm_shutdown_schedule", "virtual_machine_id") {
+		if uptime, ok := shutdownScheduleUptime(schedule.Values); ok {
+			return uptime, true
+		}
+	}
+	return types.Range{}, false
+}
+
+func matchUptimeProfile(res *planResource, profiles types.UptimeProfiles) (types.UptimeProfile, bool) {
+	for _, profile := range profiles.Profiles {
+		if profile.Address != "" && (profile.Address == res.Address || profile.Address == res.ConfigAddress) {
+			return profile, true
+		}
+	}
+	tags, _ := res.Values["tags"].(map[string]interface{})
+	for _, profile := range profiles.Profiles {
+		if profile.Address == "" && len(profile.Tags) > 0 && tagsMatch(tags, profile.Tags) {
+			return profile, true
+		}
+	}
+	for _, profile := range profiles.Profiles {
+		if profile.Address == "" && len(profile.Tags) == 0 && profile.ResourceType == res.Type {
+			return profile, true
+		}
+	}
+	return types.UptimeProfile{}, false
+}
+
+func tagsMatch(tags map[string]interface{}, want map[string]string) bool {
+	for key, value := range want {
+		if tag, _ := tags[key].(string); tag != value {
+			return false
+		}
+	}
+	return true
+}
+
+// shutdownScheduleUptime expects the VM to run from DEFAULT_WORKDAY_START until the daily shutdown on weekdays, but it
+// could be started every day of the week
+func shutdownScheduleUptime(schedule map[string]interface{}) (types.Range, bool) {
+	if enabled, ok := schedule["enabled"].(bool); ok && !enabled {
+		return types.Range{}, false
+	}
+	shutdown, err := types.ParseClockHours(getString(schedule, "daily_recurrence_time"))
+	if err != nil {
+		return types.Range{}, false
+	}
+	daily := types.DailyHours(DEFAULT_WORKDAY_START, shutdown)
+	weekdays := 5 * daily / types.WEEK_HOURS
+	return types.Range{Min: weekdays, Expected: weekdays, Max: 7 * daily / types.WEEK_HOURS}, true
+}
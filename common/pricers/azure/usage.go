@@ -0,0 +1,110 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+)
+
+// usageKeys are the quantities each resource type reads from a usage profile, which a usage template is generated from
+var usageKeys = map[string][]string{
+	"azurerm_virtual_network":               {"peering_egress_gb", "peering_ingress_gb", "internet_egress_gb"},
+	"azurerm_storage_account":               {"storage_gb", "write_operations", "read_operations", "other_operations", "geo_replication_gb"},
+	"azurerm_storage_share":                 {"storage_gb"},
+	"azurerm_mssql_database":                {"active_hours", "storage_gb"},
+	"azurerm_postgresql_flexible_server":    {"backup_storage_gb"},
+	"azurerm_mysql_flexible_server":         {"backup_storage_gb"},
+	"azurerm_postgresql_server":             {"backup_storage_gb"},
+	"azurerm_mysql_server":                  {"backup_storage_gb"},
+	"azurerm_cosmosdb_account":              {"storage_gb", "request_units"},
+	"azurerm_cosmosdb_sql_database":         {"average_throughput"},
+	"azurerm_cosmosdb_sql_container":        {"average_throughput"},
+	"azurerm_cosmosdb_mongo_database":       {"average_throughput"},
+	"azurerm_cosmosdb_mongo_collection":     {"average_throughput"},
+	"azurerm_cosmosdb_cassandra_keyspace":   {"average_throughput"},
+	"azurerm_cosmosdb_cassandra_table":      {"average_throughput"},
+	"azurerm_cosmosdb_gremlin_database":     {"average_throughput"},
+	"azurerm_cosmosdb_gremlin_graph":        {"average_throughput"},
+	"azurerm_cosmosdb_table":                {"average_throughput"},
+	"azurerm_application_gateway":           {"capacity_units", "data_processed_gb"},
+	"azurerm_lb":                            {"data_processed_gb"},
+	"azurerm_nat_gateway":                   {"data_processed_gb"},
+	"azurerm_express_route_circuit":         {"outbound_data_gb"},
+	"azurerm_virtual_hub":                   {"data_processed_gb"},
+	"azurerm_firewall":                      {"data_processed_gb"},
+	"azurerm_container_group":               {"running_hours"},
+	"azurerm_container_registry":            {"storage_gb"},
+	"azurerm_key_vault":                     {"operations", "advanced_key_operations", "certificate_renewals"},
+	"azurerm_log_analytics_workspace":       {"ingestion_gb"},
+	"azurerm_function_app":                  {"executions", "gb_seconds"},
+	"azurerm_linux_function_app":            {"executions", "gb_seconds"},
+	"azurerm_windows_function_app":          {"executions", "gb_seconds"},
+	"azurerm_eventhub_namespace":            {"ingress_events"},
+	"azurerm_servicebus_namespace":          {"operations"},
+	"azurerm_eventgrid_topic":               {"operations"},
+	"azurerm_eventgrid_domain":              {"operations"},
+	"azurerm_eventgrid_system_topic":        {"operations"},
+	"azurerm_bastion_host":                  {"outbound_data_gb"},
+	"azurerm_private_endpoint":              {"ingress_gb", "egress_gb"},
+	"azurerm_dns_zone":                      {"queries"},
+	"azurerm_private_dns_zone":              {"queries"},
+	"azurerm_cdn_frontdoor_profile":         {"requests", "outbound_data_gb"},
+	"azurerm_frontdoor":                     {"outbound_data_gb"},
+	"azurerm_frontdoor_firewall_policy":     {"requests"},
+	"azurerm_cdn_frontdoor_firewall_policy": {"requests"},
+	"azurerm_cdn_endpoint":                  {"outbound_data_gb"},
+	"azurerm_traffic_manager_profile":       {"dns_queries"},
+	"azurerm_api_management":                {"calls"},
+	"azurerm_network_watcher_flow_log":      {"logs_collected_gb"},
+}
+
+// note is a zero cost pricer that explains something about the estimate of the resource it's added to, such as the
+// usage it's missing
+type note string
+
+func (n note) GetHourlyPrice(context.Context) types.Range {
+	return types.Range{}
+}
+
+// UsageTemplate lists the usage keys of every resource in the plan that reads one, by the resource's address.  The keys
+// are left without quantities, since any quantity given in a usage profile is priced, zero included.
+func UsageTemplate(jsonBlob string) (map[string][]string, error) {
+	var pf types.PlanFile
+	if err := json.Unmarshal([]byte(jsonBlob), &pf); err != nil {
+		return nil, err
+	}
+	template := map[string][]string{}
+	for _, res := range newPlanGraph(pf).Resources {
+		if keys, ok := usageKeys[res.Type]; ok {
+			template[res.Address] = keys
+		}
+	}
+	return template, nil
+}
+
+// usageMeter is a monthly meter for a quantity from the usage profile, or nil when the profile doesn't give one
+func usageMeter(usage types.Usage, key, filter string) *meter {
+	quantity, ok := usage.Get(key)
+	if !ok {
+		return nil
+	}
+	return monthlyMeter(filter, quantity)
+}
+
+// tieredMeter prices a usage meter across its pricing tiers, e.g. storage that gets cheaper per GB past 50TB
+func tieredMeter(m *meter) *meter {
+	if m != nil {
+		m.Tiered = true
+	}
+	return m
+}
+
+// appendMeters appends the meters that were priced, skipping the usage meters that had no quantity
+func appendMeters(pricers []types.Priceable, meters ...*meter) []types.Priceable {
+	for _, m := range meters {
+		if m != nil {
+			pricers = append(pricers, m)
+		}
+	}
+	return pricers
+}
@@ -3,9 +3,11 @@ package azure
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/zparnold/terraform-cost-estimator/common/types"
 	"k8s.io/klog"
+	"strings"
 )
 
 // Response is of type APIGatewayProxyResponse since we're leveraging the
@@ -14,123 +16,575 @@ import (
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 type Response events.APIGatewayProxyResponse
 
-func PricePlanFile(ctx context.Context, jsonBlob string, priceType PricingScheme) (float64, []string, []string, error) {
+// pricedResource is a resource from the plan along with everything that contributes to its cost, e.g. a virtual machine
+// and its disks
+type pricedResource struct {
+	resource *planResource
+	pricers  []types.Priceable
+}
+
+// PricingOptions are the caller's choices about how a plan is priced
+type PricingOptions struct {
+	Scheme PricingScheme
+	//The number of hours in a month, types.MONTH_HOURS when zero
+	MonthHours float64
+	Uptime     types.UptimeProfiles
+	Usage      types.UsageProfile
+}
+
+func PricePlanFile(ctx context.Context, jsonBlob string, opts PricingOptions) (types.ApiResp, error) {
 	var unsupportedResources []string
 	var unestimateableResources []string
 	var pf types.PlanFile
 	err := json.Unmarshal([]byte(jsonBlob), &pf)
 	if err != nil {
 		klog.Error(err)
-		return 0.0, []string{}, []string{}, err
+		return types.ApiResp{}, err
 	}
-	var hourlyPrice float64
-	var resources []types.Priceable
+	priceType := opts.Scheme
+	if opts.MonthHours > 0 {
+		ctx = types.WithMonthHours(ctx, opts.MonthHours)
+	}
+	monthHours := types.MonthHours(ctx)
+	if err := opts.Uptime.Validate(); err != nil {
+		return types.ApiResp{}, err
+	}
+	if err := opts.Usage.Validate(); err != nil {
+		return types.ApiResp{}, err
+	}
+	var priced []pricedResource
+	//The usage already priced for function apps on a consumption plan, which share a monthly free grant
+	functionsUsed := map[string]float64{}
+	//Defender plans are priced by the resources they protect, so only once the rest of the plan has been
+	var defenderSubscriptions []*planResource
 
-	for _, change := range pf.ResourceChanges {
+	graph := newPlanGraph(pf)
+	for _, res := range graph.Resources {
+		var resources []types.Priceable
+		usage := opts.Usage.For(res.Type, res.ConfigAddress, res.Address)
 		//we only want to price Azure API changes
-		if change.Provider == "registry.terraform.io/hashicorp/azurerm" {
+		if res.Provider == "registry.terraform.io/hashicorp/azurerm" {
 			//Until I find a better way we need to explicitly opt-in price types
-			switch change.Type {
+			switch res.Type {
 			case "azurerm_linux_virtual_machine":
 				resources = append(resources, &VirtualMachine{
-					Size:          change.Change.After.(map[string]interface{})["size"].(string),
-					Location:      change.Change.After.(map[string]interface{})["location"].(string),
+					Size:          res.Values["size"].(string),
+					Location:      res.Values["location"].(string),
 					Count:         1.0,
-					IsSpotEnabled: change.Change.After.(map[string]interface{})["priority"].(string) == "Spot",
+					IsSpotEnabled: res.Values["priority"].(string) == "Spot",
 					IsWindows:     false,
 					PricingScheme: priceType,
 				})
 			case "azurerm_windows_virtual_machine":
 				resources = append(resources, &VirtualMachine{
-					Size:          change.Change.After.(map[string]interface{})["size"].(string),
-					Location:      change.Change.After.(map[string]interface{})["location"].(string),
+					Size:          res.Values["size"].(string),
+					Location:      res.Values["location"].(string),
 					Count:         1.0,
-					IsSpotEnabled: change.Change.After.(map[string]interface{})["priority"].(string) == "Spot",
+					IsSpotEnabled: res.Values["priority"].(string) == "Spot",
 					IsWindows:     true,
 					PricingScheme: priceType,
 				})
 			case "azurerm_kubernetes_cluster":
 				resources = append(resources, &AksCluster{
-					IsPaid: change.Change.After.(map[string]interface{})["sku_tier"].(string) == "Paid",
-				},
-					&VirtualMachine{
-						Size:      change.Change.After.(map[string]interface{})["default_node_pool"].([]interface{})[0].(map[string]interface{})["vm_size"].(string),
-						Location:  change.Change.After.(map[string]interface{})["location"].(string),
-						Count:     change.Change.After.(map[string]interface{})["default_node_pool"].([]interface{})[0].(map[string]interface{})["node_count"].(float64),
-						IsWindows: false,
-					})
+					SkuTier: getString(res.Values, "sku_tier"),
+				})
+				resources = append(resources, aksNodePoolPricers(getBlock(res.Values, "default_node_pool"), getString(res.Values, "location"), priceType)...)
+			case "azurerm_kubernetes_cluster_node_pool":
+				//Node pools don't carry a location of their own, so it comes from the cluster they're added to
+				clusters := graph.referencedBy(res, "kubernetes_cluster_id")
+				if len(clusters) == 0 {
+					unestimateableResources = append(unestimateableResources, res.Address)
+					break
+				}
+				resources = append(resources, aksNodePoolPricers(res.Values, getString(clusters[0].Values, "location"), priceType)...)
 			//This is where a resource that is unsupported	will fall through
 			case "azurerm_subnet":
-				unestimateableResources = append(unestimateableResources, "azurerm_subnet")
+				resources = append(resources, note("subnets are free"))
 				break
 			case "azurerm_resource_group":
-				unestimateableResources = append(unestimateableResources, "azurerm_resource_group")
+				resources = append(resources, note("resource groups are free"))
 				break
 			case "azurerm_virtual_network":
-				unestimateableResources = append(unestimateableResources, "azurerm_virtual_network")
+				//Only the data sent over a virtual network is billed, so without usage for it there's nothing to price
+				if pricers := virtualNetworkPricers(res, usage); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else if plan := graph.linkedResource(res, "azurerm_network_ddos_protection_plan", "ddos_protection_plan.id"); plan != nil {
+					resources = append(resources, note(fmt.Sprintf("DDoS protection is covered by the cost of %s", plan.Address)))
+				} else {
+					unestimateableResources = append(unestimateableResources, res.Address)
+				}
 				break
 			case "azurerm_network_interface":
-				unestimateableResources = append(unestimateableResources, "azurerm_network_interface")
+				resources = append(resources, note("network interfaces are free"))
 				break
 			case "azurerm_virtual_machine_scale_set":
 				var isWindows bool
-				if len(change.Change.After.(map[string]interface{})["os_profile_windows_config"].([]interface{})) > 0 {
+				if len(getBlocks(res.Values, "os_profile_windows_config")) > 0 {
 					isWindows = true
 				}
-				resources = append(resources, &VirtualMachine{
-					IsWindows: isWindows,
-					Count:     change.Change.After.(map[string]interface{})["sku"].([]interface{})[0].(map[string]interface{})["capacity"].(float64),
-					Size:      change.Change.After.(map[string]interface{})["sku"].([]interface{})[0].(map[string]interface{})["name"].(string),
-					Location:  change.Change.After.(map[string]interface{})["location"].(string),
-				})
+				sku := getBlock(res.Values, "sku")
+				capacity, _ := getFloat(sku, "capacity")
+				resources = append(resources, scaleSetPricer(graph, res, getString(sku, "name"), capacity, isWindows, priceType))
 				break
 			case "azurerm_virtual_machine":
-				var isWindows bool
-				if len(change.Change.After.(map[string]interface{})["os_profile_windows_config"].([]interface{})) > 0 {
-					isWindows = true
-				}
-				resources = append(resources, &VirtualMachine{
-					IsWindows: isWindows,
-					Count:     1,
-					Size:      change.Change.After.(map[string]interface{})["vm_size"].(string),
-					Location:  change.Change.After.(map[string]interface{})["location"].(string),
-				})
+				resources = append(resources, legacyVirtualMachinePricers(res.Values, priceType)...)
 				break
 			case "azurerm_windows_virtual_machine_scale_set":
-				resources = append(resources, &VirtualMachine{
-					IsWindows: true,
-					Count:     change.Change.After.(map[string]interface{})["instances"].(float64),
-					Size:      change.Change.After.(map[string]interface{})["sku"].(string),
-					Location:  change.Change.After.(map[string]interface{})["location"].(string),
-				})
+				instances, _ := getFloat(res.Values, "instances")
+				resources = append(resources, scaleSetPricer(graph, res, getString(res.Values, "sku"), instances, true, priceType))
 				break
 			case "azurerm_linux_virtual_machine_scale_set":
-				resources = append(resources, &VirtualMachine{
-					IsWindows: false,
-					Count:     change.Change.After.(map[string]interface{})["instances"].(float64),
-					Size:      change.Change.After.(map[string]interface{})["sku"].(string),
-					Location:  change.Change.After.(map[string]interface{})["location"].(string),
-					//TODO make helpers to grab a key of desired type from chage struct
-				})
+				instances, _ := getFloat(res.Values, "instances")
+				resources = append(resources, scaleSetPricer(graph, res, getString(res.Values, "sku"), instances, false, priceType))
+				break
+			case "azurerm_dev_test_global_vm_shutdown_schedule":
+				//Shutdown schedules are free, they're priced as part of the virtual machine they stop
+				resources = append(resources, note("free, its schedule is priced as part of the virtual machine it stops"))
+				break
+			case "azurerm_monitor_autoscale_setting":
+				//Autoscale settings are free, their capacity profiles are priced as part of the scale set they target
+				resources = append(resources, note("free, its capacity is priced as part of the resource it scales"))
+				break
+			case "azurerm_storage_account":
+				resources = append(resources, newStorageAccount(res.Values).pricers(usage)...)
+				break
+			case "azurerm_storage_share":
+				//storage_account_name was replaced by storage_account_id in v4 of the provider
+				account := graph.linkedResource(res, "azurerm_storage_account", "storage_account_name", "storage_account_id")
+				if account == nil {
+					unestimateableResources = append(unestimateableResources, res.Address)
+					break
+				}
+				resources = append(resources, storageSharePricers(newStorageAccount(account.Values), res.Values, usage)...)
+				break
+			case "azurerm_app_service_plan", "azurerm_service_plan":
+				resources = append(resources, newAppServicePlan(graph, res).pricers()...)
+				break
+			case "azurerm_app_service", "azurerm_app_service_slot", "azurerm_linux_web_app", "azurerm_windows_web_app",
+				"azurerm_linux_web_app_slot", "azurerm_windows_web_app_slot", "azurerm_function_app", "azurerm_function_app_slot",
+				"azurerm_linux_function_app", "azurerm_windows_function_app":
+				//Apps on a dedicated plan are paid for by the plan's instances, function apps on a consumption plan by their executions
+				//The plan may be an existing one that isn't part of this plan file, which leaves nothing to price it with
+				plan := appServicePlanFor(graph, res)
+				if plan == nil {
+					unestimateableResources = append(unestimateableResources, res.Address)
+					break
+				}
+				if newAppServicePlan(graph, plan).isConsumption() {
+					resources = append(resources, functionAppConsumptionPricers(getString(plan.Values, "location"), usage, functionsUsed)...)
+					break
+				}
+				resources = append(resources, note(fmt.Sprintf("covered by the cost of %s", plan.Address)))
+				break
+			case "azurerm_mssql_server", "azurerm_sql_server":
+				//Logical servers are free, only their databases and pools are billed
+				resources = append(resources, note("free, only the databases and elastic pools on it are billed"))
+				break
+			case "azurerm_mssql_database", "azurerm_sql_database":
+				var pool *planResource
+				var location, skuName string
+				var maxSizeGb float64
+				if res.Type == "azurerm_mssql_database" {
+					//Databases are created on a server, which is where their location comes from
+					server := graph.linkedResource(res, "azurerm_mssql_server", "server_id")
+					if server == nil {
+						unestimateableResources = append(unestimateableResources, res.Address)
+						break
+					}
+					pool = graph.linkedResource(res, "azurerm_mssql_elasticpool", "elastic_pool_id")
+					location, skuName = getString(server.Values, "location"), getString(res.Values, "sku_name")
+					maxSizeGb, _ = getFloat(res.Values, "max_size_gb")
+				} else {
+					pool = graph.linkedResource(res, "azurerm_sql_elasticpool", "elastic_pool_name")
+					location, skuName = getString(res.Values, "location"), getString(res.Values, "requested_service_objective_name")
+					maxSizeGb = sqlDatabaseMaxSizeGb(res.Values)
+				}
+				if pool != nil {
+					resources = append(resources, note(fmt.Sprintf("covered by the cost of %s", pool.Address)))
+					break
+				}
+				if getString(res.Values, "elastic_pool_id") != "" || getString(res.Values, "elastic_pool_name") != "" || skuName == "ElasticPool" {
+					resources = append(resources, note("covered by the cost of its elastic pool"))
+					break
+				}
+				if skuName == "" && res.Type == "azurerm_sql_database" {
+					//Without a service objective a legacy database runs at the default of its edition
+					if skuName = sqlEditionDefaultSkus[getString(res.Values, "edition")]; skuName == "" {
+						unsupportedResources = append(unsupportedResources, res.Address)
+						break
+					}
+				}
+				if pricers := mssqlDatabasePricers(location, res.Values, skuName, maxSizeGb, usage, monthHours); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					unsupportedResources = append(unsupportedResources, res.Address)
+				}
+				break
+			case "azurerm_mssql_elasticpool":
+				resources = append(resources, mssqlElasticPoolPricers(res.Values, monthHours)...)
+				break
+			case "azurerm_sql_elasticpool":
+				dtu, _ := getFloat(res.Values, "dtu")
+				resources = append(resources, sqlElasticPoolDtuPricers(getString(res.Values, "location"), getString(res.Values, "edition"), dtu)...)
+				break
+			case "azurerm_mssql_managed_instance":
+				resources = append(resources, mssqlManagedInstancePricers(res.Values, monthHours)...)
+				break
+			case "azurerm_postgresql_flexible_server", "azurerm_mysql_flexible_server", "azurerm_postgresql_server", "azurerm_mysql_server":
+				if pricers := newDatabaseServer(res, priceType).pricers(res.Values, usage); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					unsupportedResources = append(unsupportedResources, res.Address)
+				}
+				break
+			case "azurerm_postgresql_flexible_server_database", "azurerm_postgresql_flexible_server_configuration",
+				"azurerm_postgresql_flexible_server_firewall_rule", "azurerm_mysql_flexible_database", "azurerm_mysql_flexible_server_configuration",
+				"azurerm_mysql_flexible_server_firewall_rule", "azurerm_postgresql_database", "azurerm_postgresql_configuration",
+				"azurerm_postgresql_firewall_rule", "azurerm_mysql_database", "azurerm_mysql_configuration", "azurerm_mysql_firewall_rule":
+				//Databases and settings are free, they're priced as part of the server they're on
+				resources = append(resources, note("free, priced as part of the server it's on"))
+				break
+			case "azurerm_cosmosdb_account":
+				resources = append(resources, newCosmosDbAccount(res.Values).pricers(usage)...)
+				break
+			case "azurerm_cosmosdb_sql_database", "azurerm_cosmosdb_sql_container", "azurerm_cosmosdb_mongo_database",
+				"azurerm_cosmosdb_mongo_collection", "azurerm_cosmosdb_cassandra_keyspace", "azurerm_cosmosdb_cassandra_table",
+				"azurerm_cosmosdb_gremlin_database", "azurerm_cosmosdb_gremlin_graph", "azurerm_cosmosdb_table":
+				account := cosmosDbAccountFor(graph, res)
+				if account == nil {
+					unestimateableResources = append(unestimateableResources, res.Address)
+					break
+				}
+				cosmos := newCosmosDbAccount(account.Values)
+				if cosmos.Serverless {
+					resources = append(resources, note(fmt.Sprintf("billed through the request units of %s", account.Address)))
+				} else if pricers := cosmos.throughputPricers(res.Values, usage); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					resources = append(resources, note(cosmosDbUnprovisionedNotes[res.Type]))
+				}
+				break
+			case "azurerm_redis_cache":
+				resources = append(resources, newRedisCache(res.Values, priceType).pricers()...)
+				break
+			case "azurerm_redis_enterprise_cluster":
+				if pricers := redisEnterprisePricers(res.Values, priceType); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					unsupportedResources = append(unsupportedResources, res.Address)
+				}
+				break
+			case "azurerm_redis_enterprise_database", "azurerm_redis_firewall_rule":
+				//Databases and firewall rules are free, they're priced as part of the cache they're on
+				resources = append(resources, note("free, priced as part of the cache it's on"))
+				break
+			case "azurerm_application_gateway":
+				resources = append(resources, newApplicationGateway(res.Values).pricers(usage)...)
+				break
+			case "azurerm_web_application_firewall_policy":
+				//WAF policies are billed through the WAF tier of the gateways they're attached to
+				gateways := wafPolicyGateways(graph, res)
+				if len(gateways) == 0 {
+					resources = append(resources, note("not attached to any gateway, so there's no WAF tier to bill it through"))
+					break
+				}
+				var addresses []string
+				for _, gateway := range gateways {
+					addresses = append(addresses, gateway.Address)
+				}
+				resources = append(resources, note(fmt.Sprintf("covered by the cost of %s", strings.Join(addresses, ", "))))
+				break
+			case "azurerm_public_ip":
+				if prefix := graph.linkedResource(res, "azurerm_public_ip_prefix", "public_ip_prefix_id"); prefix != nil {
+					resources = append(resources, note(fmt.Sprintf("covered by the cost of %s", prefix.Address)))
+					break
+				}
+				if getString(res.Values, "public_ip_prefix_id") != "" {
+					resources = append(resources, note("covered by the cost of its public IP prefix"))
+					break
+				}
+				resources = append(resources, publicIpPricers(res.Values)...)
+				break
+			case "azurerm_security_center_subscription_pricing":
+				defenderSubscriptions = append(defenderSubscriptions, res)
+				break
+			case "azurerm_security_center_contact", "azurerm_security_center_setting", "azurerm_security_center_workspace",
+				"azurerm_security_center_auto_provisioning":
+				//Defender settings are free, only the plans they configure are billed
+				resources = append(resources, note("free, only the Defender plans it configures are billed"))
+				break
+			case "azurerm_network_ddos_protection_plan":
+				resources = append(resources, ddosProtectionPlanPricers(graph, res)...)
+				break
+			case "azurerm_network_watcher_flow_log":
+				resources = append(resources, flowLogPricers(graph, res, usage)...)
+				break
+			case "azurerm_network_watcher":
+				//Network Watcher is free, its flow logs are priced on their own
+				resources = append(resources, note("free, its flow logs are priced on their own"))
+				break
+			case "azurerm_public_ip_prefix":
+				resources = append(resources, publicIpPrefixPricers(res.Values)...)
+				break
+			case "azurerm_lb":
+				switch getString(res.Values, "sku") {
+				case "Standard":
+					resources = append(resources, loadBalancerPricers(graph, res, usage)...)
+				case "Gateway":
+					unsupportedResources = append(unsupportedResources, res.Address)
+				default:
+					//Basic load balancers are free
+					resources = append(resources, note("Basic load balancers are free"))
+				}
+				break
+			case "azurerm_lb_rule", "azurerm_lb_outbound_rule", "azurerm_lb_nat_rule", "azurerm_lb_nat_pool", "azurerm_lb_probe",
+				"azurerm_lb_backend_address_pool", "azurerm_lb_backend_address_pool_address", "azurerm_network_interface_backend_address_pool_association":
+				//Load balancer rules and pools are priced as part of the load balancer they're on
+				resources = append(resources, note("priced as part of the load balancer it's on"))
+				break
+			case "azurerm_nat_gateway":
+				resources = append(resources, natGatewayPricers(res.Values, usage)...)
+				break
+			case "azurerm_nat_gateway_public_ip_association", "azurerm_nat_gateway_public_ip_prefix_association", "azurerm_subnet_nat_gateway_association":
+				resources = append(resources, note("free, priced as part of the NAT gateway it associates"))
+				break
+			case "azurerm_virtual_network_gateway":
+				resources = append(resources, virtualNetworkGatewayPricers(graph, res)...)
+				break
+			case "azurerm_express_route_circuit":
+				resources = append(resources, expressRouteCircuitPricers(res.Values, usage)...)
+				break
+			case "azurerm_virtual_hub":
+				resources = append(resources, virtualHubPricers(res.Values, usage)...)
+				break
+			case "azurerm_vpn_gateway", "azurerm_express_route_gateway":
+				resources = append(resources, virtualWanGatewayPricers(graph, res)...)
+				break
+			case "azurerm_virtual_network_gateway_connection", "azurerm_vpn_gateway_connection", "azurerm_local_network_gateway",
+				"azurerm_express_route_circuit_peering", "azurerm_express_route_circuit_authorization", "azurerm_virtual_wan",
+				"azurerm_virtual_hub_connection", "azurerm_vpn_site":
+				//Connections and sites are priced as part of the gateway they connect to, and a virtual WAN is free
+				resources = append(resources, note("free, priced as part of the gateway or hub it connects to"))
+				break
+			case "azurerm_firewall":
+				resources = append(resources, firewallPricers(res.Values, usage)...)
+				break
+			case "azurerm_firewall_policy":
+				resources = append(resources, firewallPolicyPricers(graph, res)...)
+				break
+			case "azurerm_firewall_policy_rule_collection_group", "azurerm_firewall_network_rule_collection",
+				"azurerm_firewall_application_rule_collection", "azurerm_firewall_nat_rule_collection":
+				//Rules are priced as part of the firewall or policy they're in
+				resources = append(resources, note("priced as part of the firewall or policy it's in"))
+				break
+			case "azurerm_container_group":
+				resources = append(resources, containerGroupPricers(res.Values, usage, monthHours)...)
+				break
+			case "azurerm_container_registry":
+				resources = append(resources, containerRegistryPricers(res.Values, usage)...)
+				break
+			case "azurerm_key_vault":
+				resources = append(resources, keyVaultPricers(res.Values, usage)...)
+				break
+			case "azurerm_key_vault_key":
+				//Keys don't carry a location of their own, so HSM-protected keys are priced in the vault's region
+				vault := graph.linkedResource(res, "azurerm_key_vault", "key_vault_id")
+				if vault == nil {
+					unestimateableResources = append(unestimateableResources, res.Address)
+					break
+				}
+				if pricers := keyVaultKeyPricers(vault, res.Values); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					resources = append(resources, note(fmt.Sprintf("software-protected keys are billed through the operations of %s", vault.Address)))
+				}
+				break
+			case "azurerm_key_vault_secret", "azurerm_key_vault_access_policy", "azurerm_key_vault_certificate",
+				"azurerm_container_registry_webhook", "azurerm_container_registry_scope_map", "azurerm_container_registry_token",
+				"azurerm_log_analytics_solution", "azurerm_log_analytics_saved_search":
+				//Billed through the operations, storage or ingestion of the vault, registry or workspace they belong to
+				resources = append(resources, note("billed through the operations, storage or ingestion of the vault, registry or workspace it belongs to"))
+				break
+			case "azurerm_log_analytics_workspace":
+				resources = append(resources, logAnalyticsWorkspacePricers(res.Values, usage, monthHours)...)
+				break
+			case "azurerm_eventhub_namespace":
+				if cluster := graph.linkedResource(res, "azurerm_eventhub_cluster", "dedicated_cluster_id"); cluster != nil {
+					resources = append(resources, note(fmt.Sprintf("covered by the cost of %s", cluster.Address)))
+					break
+				}
+				resources = append(resources, eventHubNamespacePricers(graph, res, usage)...)
+				break
+			case "azurerm_eventhub_cluster":
+				resources = append(resources, eventHubClusterPricers(res.Values)...)
+				break
+			case "azurerm_servicebus_namespace":
+				resources = append(resources, serviceBusNamespacePricers(res.Values, usage)...)
+				break
+			case "azurerm_eventgrid_topic", "azurerm_eventgrid_domain", "azurerm_eventgrid_system_topic":
+				resources = append(resources, eventGridTopicPricers(res.Values, usage)...)
+				break
+			case "azurerm_eventhub", "azurerm_eventhub_consumer_group", "azurerm_eventhub_authorization_rule",
+				"azurerm_eventhub_namespace_authorization_rule", "azurerm_servicebus_queue", "azurerm_servicebus_topic",
+				"azurerm_servicebus_subscription", "azurerm_servicebus_namespace_authorization_rule", "azurerm_eventgrid_event_subscription",
+				"azurerm_eventgrid_system_topic_event_subscription", "azurerm_eventgrid_domain_topic":
+				//Priced as part of the namespace, topic or domain they belong to
+				resources = append(resources, note("priced as part of the namespace, topic or domain it belongs to"))
+				break
+			case "azurerm_bastion_host":
+				resources = append(resources, bastionHostPricers(res.Values, usage)...)
+				break
+			case "azurerm_private_endpoint":
+				resources = append(resources, privateEndpointPricers(res.Values, usage)...)
+				break
+			case "azurerm_dns_zone", "azurerm_private_dns_zone":
+				resources = append(resources, dnsZonePricers(res.Type == "azurerm_private_dns_zone", usage)...)
+				break
+			case "azurerm_dns_a_record", "azurerm_dns_aaaa_record", "azurerm_dns_caa_record", "azurerm_dns_cname_record",
+				"azurerm_dns_mx_record", "azurerm_dns_ns_record", "azurerm_dns_ptr_record", "azurerm_dns_srv_record", "azurerm_dns_txt_record",
+				"azurerm_private_dns_a_record", "azurerm_private_dns_aaaa_record", "azurerm_private_dns_cname_record", "azurerm_private_dns_mx_record",
+				"azurerm_private_dns_ptr_record", "azurerm_private_dns_srv_record", "azurerm_private_dns_txt_record",
+				"azurerm_private_dns_zone_virtual_network_link":
+				//Records and links are covered by the zone they're in
+				resources = append(resources, note("covered by the cost of the zone it's in"))
+				break
+			case "azurerm_cdn_frontdoor_profile":
+				resources = append(resources, frontDoorProfilePricers(res.Values, usage)...)
+				break
+			case "azurerm_frontdoor":
+				resources = append(resources, classicFrontDoorPricers(res.Values, usage)...)
+				break
+			case "azurerm_frontdoor_firewall_policy", "azurerm_cdn_frontdoor_firewall_policy":
+				//Premium profiles include their WAF
+				if getString(res.Values, "sku_name") == "Premium_AzureFrontDoor" {
+					resources = append(resources, note("WAF policies are included in the cost of Premium Front Door profiles"))
+					break
+				}
+				resources = append(resources, frontDoorWafPolicyPricers(res.Values, usage)...)
+				break
+			case "azurerm_cdn_endpoint":
+				profile := graph.linkedResource(res, "azurerm_cdn_profile", "profile_name")
+				if profile == nil {
+					unestimateableResources = append(unestimateableResources, res.Address)
+					break
+				}
+				if pricers := cdnEndpointPricers(profile, usage); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					unsupportedResources = append(unsupportedResources, res.Address)
+				}
+				break
+			case "azurerm_traffic_manager_profile":
+				resources = append(resources, trafficManagerProfilePricers(graph, res, usage)...)
+				break
+			case "azurerm_cdn_profile", "azurerm_traffic_manager_endpoint", "azurerm_traffic_manager_azure_endpoint",
+				"azurerm_traffic_manager_external_endpoint", "azurerm_traffic_manager_nested_endpoint", "azurerm_cdn_frontdoor_endpoint",
+				"azurerm_cdn_frontdoor_origin_group", "azurerm_cdn_frontdoor_origin", "azurerm_cdn_frontdoor_route",
+				"azurerm_cdn_frontdoor_rule_set", "azurerm_cdn_frontdoor_rule", "azurerm_cdn_frontdoor_security_policy",
+				"azurerm_cdn_frontdoor_custom_domain":
+				//CDN profiles are billed through their endpoints, and endpoints, routes and origins through their profile
+				resources = append(resources, note("billed through the endpoints of its CDN profile, or the Front Door profile it belongs to"))
+				break
+			case "azurerm_api_management":
+				if pricers := apiManagementPricers(res.Values, usage); len(pricers) > 0 {
+					resources = append(resources, pricers...)
+				} else {
+					unsupportedResources = append(unsupportedResources, res.Address)
+				}
+				break
+			case "azurerm_api_management_api", "azurerm_api_management_api_operation", "azurerm_api_management_api_policy",
+				"azurerm_api_management_api_operation_policy", "azurerm_api_management_product", "azurerm_api_management_product_api",
+				"azurerm_api_management_product_policy", "azurerm_api_management_subscription", "azurerm_api_management_named_value",
+				"azurerm_api_management_logger", "azurerm_api_management_backend", "azurerm_api_management_user",
+				"azurerm_api_management_group", "azurerm_api_management_custom_domain", "azurerm_api_management_diagnostic":
+				//APIs and their configuration are priced as part of the API Management service they're in
+				resources = append(resources, note("priced as part of the API Management service it's in"))
 				break
 			case "azurerm_managed_disk":
 				resources = append(resources, &AzureDisk{
-					Location: change.Change.After.(map[string]interface{})["location"].(string),
-					SizeInGb: change.Change.After.(map[string]interface{})["disk_size_gb"].(float64),
-					SkuTier:  change.Change.After.(map[string]interface{})["storage_account_type"].(string),
+					Location: res.Values["location"].(string),
+					SizeInGb: res.Values["disk_size_gb"].(float64),
+					SkuTier:  res.Values["storage_account_type"].(string),
 					Count:    1,
 				})
 				break
 			default:
-				unsupportedResources = append(unsupportedResources, change.Address)
+				unsupportedResources = append(unsupportedResources, res.Address)
 				break
 			}
 		}
+		if len(resources) > 0 {
+			if uptime, ok := resolveUptime(graph, res, opts.Uptime, monthHours); ok {
+				for _, pricer := range resources {
+					if scheduled, ok := pricer.(scheduledPricer); ok {
+						scheduled.setUptime(uptime)
+					}
+				}
+			}
+			priced = append(priced, pricedResource{resource: res, pricers: resources})
+		}
 	}
+	priced = append(priced, defenderSubscriptionPricers(graph, defenderSubscriptions, priced)...)
 
-	for _, res := range resources {
-		hourlyPrice += res.GetHourlyPrice(ctx)
+	var r types.ApiResp
+	var total types.Range
+	for _, p := range priced {
+		var hourly types.Range
+		var notes []string
+		for _, pricer := range p.pricers {
+			if n, ok := pricer.(note); ok {
+				notes = append(notes, string(n))
+			}
+			hourly = hourly.Add(pricer.GetHourlyPrice(ctx))
+		}
+		total = total.Add(hourly)
+		r.PriceItems = append(r.PriceItems, types.ApiRespPriceItem{
+			Address:      p.resource.Address,
+			ResourceType: p.resource.Type,
+			Estimate:     types.NewEstimateTotal(hourly, monthHours),
+			Notes:        notes,
+		})
 	}
+	r.TotalEstimate = types.NewEstimateTotal(total, monthHours)
+	r.UnsupportedResources = unsupportedResources
+	r.UnestimateableResources = unestimateableResources
+	return r, nil
+}
+
+// Helpers to grab a key of the desired type from a change's "after" values.  Values that are null or unknown until apply
+// come through as nil, so these return the zero value instead of panicking on a type assertion.
+func getString(values map[string]interface{}, key string) string {
+	s, _ := values[key].(string)
+	return s
+}
+
+func getFloat(values map[string]interface{}, key string) (float64, bool) {
+	f, ok := values[key].(float64)
+	return f, ok
+}
 
-	return hourlyPrice, unsupportedResources, unestimateableResources, nil
+func getBool(values map[string]interface{}, key string) bool {
+	b, _ := values[key].(bool)
+	return b
+}
+
+// getBlocks returns the nested blocks stored under key, skipping anything that isn't an object
+func getBlocks(values map[string]interface{}, key string) []map[string]interface{} {
+	var blocks []map[string]interface{}
+	list, _ := values[key].([]interface{})
+	for _, item := range list {
+		if block, ok := item.(map[string]interface{}); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// getBlock returns the first nested block stored under key, or nil when the block isn't set
+func getBlock(values map[string]interface{}, key string) map[string]interface{} {
+	blocks := getBlocks(values, key)
+	if len(blocks) == 0 {
+		return nil
+	}
+	return blocks[0]
 }
@@ -25,13 +25,18 @@ var PricingSchemeLookup = map[string]PricingScheme{
 }
 
 type VirtualMachine struct {
-	IsWindows     bool
-	Size          string
-	Location      string
-	Count         float64
+	IsWindows bool
+	Size      string
+	Location  string
+	Count     float64
+	//The range an autoscaling group of instances can scale between.  Both are zero when the count is fixed.
+	MinCount      float64
+	MaxCount      float64
 	IsSpotEnabled bool
 	IsLowPriority bool
 	PricingScheme PricingScheme
+	//The share of the month the instances are running for, nil when they are always on
+	Uptime *types.Range
 }
 
 func (v *VirtualMachine) GenerateQuery(context.Context) string {
@@ -69,12 +74,12 @@ func (v *VirtualMachine) GenerateQuery(context.Context) string {
 	return baseQuery
 }
 
-func (v *VirtualMachine) GetHourlyPrice(ctx context.Context) float64 {
+func (v *VirtualMachine) GetHourlyPrice(ctx context.Context) types.Range {
 	unitPrice := 0.0
 	vms, err := types.ExecuteAzurePriceQuery(ctx, v)
 	if err != nil {
 		klog.Error(err)
-		return unitPrice
+		return types.FixedRange(unitPrice)
 	}
 	//Assume that the first one is the one we want
 	if len(vms.Items) > 0 {
@@ -101,7 +106,24 @@ func (v *VirtualMachine) GetHourlyPrice(ctx context.Context) float64 {
 		}
 	}
 
-	return unitPrice * v.Count
+	count := v.countRange()
+	//Reserved instances are paid for whether or not they're running
+	if v.Uptime != nil && !useReservationBilling(*v) {
+		count = count.Multiply(*v.Uptime)
+	}
+	return count.Scale(unitPrice)
+}
+
+func (v *VirtualMachine) setUptime(uptime types.Range) {
+	v.Uptime = &uptime
+}
+
+// countRange is the number of instances this VM could be running, as a range when it can autoscale
+func (v *VirtualMachine) countRange() types.Range {
+	if v.MaxCount == 0 {
+		return types.FixedRange(v.Count)
+	}
+	return types.Range{Min: v.MinCount, Expected: v.Count, Max: v.MaxCount}
 }
 
 func useReservationBilling(v VirtualMachine) bool {
@@ -110,3 +132,74 @@ func useReservationBilling(v VirtualMachine) bool {
 	}
 	return false
 }
+
+// legacyVirtualMachinePricers prices the compute and managed disks of an azurerm_virtual_machine.  Disks that are attached
+// from an existing azurerm_managed_disk are priced by that resource, and unmanaged (vhd_uri) disks are billed through
+// their storage account, so neither are included here.
+func legacyVirtualMachinePricers(values map[string]interface{}, priceType PricingScheme) []types.Priceable {
+	location := getString(values, "location")
+	osDisk := getBlock(values, "storage_os_disk")
+	isWindows := len(getBlocks(values, "os_profile_windows_config")) > 0 ||
+		strings.EqualFold(getString(osDisk, "os_type"), "Windows")
+	if image := getBlock(values, "storage_image_reference"); image != nil {
+		isWindows = isWindows || isWindowsImage(getString(image, "publisher"), getString(image, "offer"))
+	}
+
+	pricers := []types.Priceable{&VirtualMachine{
+		IsWindows:     isWindows,
+		Count:         1,
+		Size:          getString(values, "vm_size"),
+		Location:      location,
+		PricingScheme: priceType,
+	}}
+	if osDisk != nil {
+		defaultSize := DEFAULT_LINUX_OS_DISK_GB
+		if isWindows {
+			defaultSize = DEFAULT_WINDOWS_OS_DISK_GB
+		}
+		if disk := legacyManagedDisk(osDisk, location, defaultSize); disk != nil {
+			pricers = append(pricers, disk)
+		}
+	}
+	for _, dataDisk := range getBlocks(values, "storage_data_disk") {
+		if disk := legacyManagedDisk(dataDisk, location, 0); disk != nil {
+			pricers = append(pricers, disk)
+		}
+	}
+	return pricers
+}
+
+// legacyManagedDisk converts a storage_os_disk or storage_data_disk block into an AzureDisk, returning nil for disks
+// that aren't created (and billed) as part of the virtual machine.  A disk whose size isn't known and has no default,
+// which is the case for data disks, gets a note rather than being priced as the smallest disk of its tier.
+func legacyManagedDisk(block map[string]interface{}, location string, defaultSize float64) types.Priceable {
+	if getString(block, "vhd_uri") != "" || getString(block, "create_option") == "Attach" {
+		return nil
+	}
+	sku := getString(block, "managed_disk_type")
+	if sku == "" {
+		sku = "Standard_LRS"
+	}
+	size, ok := getFloat(block, "disk_size_gb")
+	if !ok || size == 0 {
+		//disk_size_gb is unknown until apply when the disk is sized from its image
+		size = defaultSize
+	}
+	if size == 0 {
+		return note(fmt.Sprintf("the size of disk %q isn't known until apply, so it isn't priced", getString(block, "name")))
+	}
+	return &AzureDisk{
+		Location: location,
+		SizeInGb: size,
+		SkuTier:  sku,
+		Count:    1,
+	}
+}
+
+// isWindowsImage makes a best guess from a marketplace image reference as to whether it is licensed for Windows,
+// e.g. MicrosoftWindowsServer/WindowsServer or MicrosoftSQLServer/SQL2019-WS2019
+func isWindowsImage(publisher, offer string) bool {
+	publisher = strings.ToLower(publisher)
+	offer = strings.ToLower(offer)
+	return strings.Contains(publisher, "windows") || strings.Contains(offer, "windows") || strings.Contains(offer, "-ws20")
+}
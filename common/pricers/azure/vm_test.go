@@ -0,0 +1,37 @@
+package azure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLegacyManagedDisk(t *testing.T) {
+	cases := []struct {
+		name        string
+		block       map[string]interface{}
+		defaultSize float64
+		sku         string
+		size        float64
+		priced      bool
+	}{
+		{"sized data disk", map[string]interface{}{"name": "data", "disk_size_gb": 256.0, "managed_disk_type": "Premium_LRS"}, 0, "Premium_LRS", 256, true},
+		{"OS disk sized from its image", map[string]interface{}{"name": "os"}, 30, "Standard_LRS", 30, true},
+		{"unmanaged disk", map[string]interface{}{"name": "os", "vhd_uri": "https://example.blob.core.windows.net/vhds/os.vhd"}, 30, "", 0, false},
+		{"attached disk", map[string]interface{}{"name": "data", "create_option": "Attach", "disk_size_gb": 128.0}, 0, "", 0, false},
+	}
+	for _, c := range cases {
+		pricer := legacyManagedDisk(c.block, "eastus", c.defaultSize)
+		if !c.priced {
+			assert.Nil(t, pricer, c.name)
+			continue
+		}
+		if disk, ok := pricer.(*AzureDisk); assert.True(t, ok, c.name) {
+			assert.Equal(t, c.sku, disk.SkuTier, c.name)
+			assert.Equal(t, c.size, disk.SizeInGb, c.name)
+		}
+	}
+
+	// a data disk with no size isn't priced as the smallest disk of its tier
+	pricer := legacyManagedDisk(map[string]interface{}{"name": "data", "managed_disk_type": "Premium_LRS"}, "eastus", 0)
+	assert.Equal(t, note(`the size of disk "data" isn't known until apply, so it isn't priced`), pricer)
+}
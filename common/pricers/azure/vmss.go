@@ -0,0 +1,65 @@
+package azure
+
+import "math"
+
+// scaleSetPricer prices the instances of a virtual machine scale set.  When an azurerm_monitor_autoscale_setting targets
+// the scale set its capacity profiles decide how many instances it runs, otherwise the count in the plan is fixed.
+func scaleSetPricer(graph *planGraph, res *planResource, size string, count float64, isWindows bool, priceType PricingScheme) *VirtualMachine {
+	vm := &VirtualMachine{
+		IsWindows:     isWindows,
+		Count:         count,
+		Size:          size,
+		Location:      getString(res.Values, "location"),
+		IsSpotEnabled: getString(res.Values, "priority") == "Spot",
+		PricingScheme: priceType,
+	}
+	if min, expected, max, ok := linkedAutoscaleCapacity(graph, res); ok {
+		vm.MinCount, vm.Count, vm.MaxCount = min, expected, max
+	}
+	return vm
+}
+
+// linkedAutoscaleCapacity finds the capacity of the first enabled azurerm_monitor_autoscale_setting targeting res
+func linkedAutoscaleCapacity(graph *planGraph, res *planResource) (min, expected, max float64, ok bool) {
+	for _, setting := range graph.referencing(res, "azurerm_monitor_autoscale_setting", "target_resource_id") {
+		if enabled, ok := setting.Values["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		if min, expected, max, ok := autoscaleCapacity(setting.Values); ok {
+			return min, expected, max, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// autoscaleCapacity reads the capacity blocks of an autoscale setting's profiles.  The scale set can run anywhere between
+// the lowest minimum and the highest maximum of all of its profiles, and is expected to sit at the default capacity of
+// the profile that applies when no recurrence or fixed date profile does.
+func autoscaleCapacity(setting map[string]interface{}) (min, expected, max float64, ok bool) {
+	min, max = math.MaxFloat64, 0
+	var defaultProfile, firstProfile map[string]interface{}
+	for _, profile := range getBlocks(setting, "profile") {
+		capacity := getBlock(profile, "capacity")
+		if capacity == nil {
+			continue
+		}
+		profileMin, _ := getFloat(capacity, "minimum")
+		profileMax, _ := getFloat(capacity, "maximum")
+		min = math.Min(min, profileMin)
+		max = math.Max(max, profileMax)
+		if firstProfile == nil {
+			firstProfile = capacity
+		}
+		if defaultProfile == nil && getBlock(profile, "recurrence") == nil && getBlock(profile, "fixed_date") == nil {
+			defaultProfile = capacity
+		}
+	}
+	if firstProfile == nil {
+		return 0, 0, 0, false
+	}
+	if defaultProfile == nil {
+		defaultProfile = firstProfile
+	}
+	expected, _ = getFloat(defaultProfile, "default")
+	return min, expected, max, true
+}
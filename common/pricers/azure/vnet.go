@@ -0,0 +1,19 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
+)
+
+// virtualNetworkPricers prices the data a virtual network sends over peerings and out to the internet, which is only
+// known from a usage profile.  A virtual network on its own is free.
+func virtualNetworkPricers(res *planResource, usage types.Usage) []types.Priceable {
+	location := getString(res.Values, "location")
+	peering := fmt.Sprintf("serviceName eq 'Virtual Network' and productName eq 'Virtual Network Peering' and armRegionName eq '%s'", location)
+	return appendMeters(nil,
+		usageMeter(usage, "peering_egress_gb", peering+" and meterName eq 'Intra-Region Egress'"),
+		usageMeter(usage, "peering_ingress_gb", peering+" and meterName eq 'Intra-Region Ingress'"),
+		//The first 100GB a month are free, which is the bottom tier of this meter
+		tieredMeter(usageMeter(usage, "internet_egress_gb", fmt.Sprintf("serviceName eq 'Bandwidth' and armRegionName eq '%s' and meterName eq 'Standard Data Transfer Out'", location))),
+	)
+}
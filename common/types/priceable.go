@@ -4,8 +4,9 @@ import "context"
 
 /*
 An interface that allows for different ways of fetching a price as long as it represents an estimated hourly cost for
-this resource.
+this resource.  Resources whose cost isn't fixed, such as autoscaling groups, return the low, expected and high hourly
+cost they could run at; everything else returns a FixedRange.
 */
 type Priceable interface {
-	GetHourlyPrice(ctx context.Context) float64
+	GetHourlyPrice(ctx context.Context) Range
 }
@@ -0,0 +1,29 @@
+package types
+
+/*
+A low, expected and high value for anything that isn't known exactly until the resource is running, such as the node
+count of an autoscaling pool or the hourly cost of that pool.
+*/
+type Range struct {
+	Min      float64 `json:"min" yaml:"min"`
+	Expected float64 `json:"expected" yaml:"expected"`
+	Max      float64 `json:"max" yaml:"max"`
+}
+
+// FixedRange is a Range for a value that is known exactly
+func FixedRange(v float64) Range {
+	return Range{Min: v, Expected: v, Max: v}
+}
+
+func (r Range) Add(o Range) Range {
+	return Range{Min: r.Min + o.Min, Expected: r.Expected + o.Expected, Max: r.Max + o.Max}
+}
+
+// Multiply multiplies each bound by the matching bound of o, e.g. a price per instance by an instance count
+func (r Range) Multiply(o Range) Range {
+	return Range{Min: r.Min * o.Min, Expected: r.Expected * o.Expected, Max: r.Max * o.Max}
+}
+
+func (r Range) Scale(f float64) Range {
+	return Range{Min: r.Min * f, Expected: r.Expected * f, Max: r.Max * f}
+}
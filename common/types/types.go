@@ -8,20 +8,46 @@ const (
 )
 
 type ApiResp struct {
-	//Future Work
-	//PriceItems []ApiRespPriceItem `json:"price_items"`
-	UnsupportedResources    []string      `json:"unsupported_resources,omitempty",yaml:"unsupported_resources,omitempty"`
-	UnestimateableResources []string      `json:"unestimateable_resources,omitempty",yaml:"unestimateable_resources,omitempty"`
-	TotalEstimate           EstimateTotal `json:"estimate_summary",yaml:"estimate_summary"`
+	PriceItems              []ApiRespPriceItem `json:"price_items,omitempty" yaml:"price_items,omitempty"`
+	UnsupportedResources    []string           `json:"unsupported_resources,omitempty" yaml:"unsupported_resources,omitempty"`
+	UnestimateableResources []string           `json:"unestimateable_resources,omitempty" yaml:"unestimateable_resources,omitempty"`
+	TotalEstimate           EstimateTotal      `json:"estimate_summary" yaml:"estimate_summary"`
 }
+
+// The Hourly/Monthly/YearlyCost fields are the expected cost, with the Min and Max fields giving the spread around it
 type EstimateTotal struct {
-	HourlyCost  float64 `json:"hourly_cost_usd",yaml:"hourly_cost_usd"`
-	MonthlyCost float64 `json:"monthly_cost_usd",yaml:"monthly_cost_usd"`
-	YearlyCost  float64 `json:"yearly_cost_usd",yaml:"yearly_cost_usd"`
+	HourlyCost     float64 `json:"hourly_cost_usd" yaml:"hourly_cost_usd"`
+	MonthlyCost    float64 `json:"monthly_cost_usd" yaml:"monthly_cost_usd"`
+	YearlyCost     float64 `json:"yearly_cost_usd" yaml:"yearly_cost_usd"`
+	MinHourlyCost  float64 `json:"min_hourly_cost_usd" yaml:"min_hourly_cost_usd"`
+	MinMonthlyCost float64 `json:"min_monthly_cost_usd" yaml:"min_monthly_cost_usd"`
+	MinYearlyCost  float64 `json:"min_yearly_cost_usd" yaml:"min_yearly_cost_usd"`
+	MaxHourlyCost  float64 `json:"max_hourly_cost_usd" yaml:"max_hourly_cost_usd"`
+	MaxMonthlyCost float64 `json:"max_monthly_cost_usd" yaml:"max_monthly_cost_usd"`
+	MaxYearlyCost  float64 `json:"max_yearly_cost_usd" yaml:"max_yearly_cost_usd"`
 }
+
 type ApiRespPriceItem struct {
-	ResourceType string  `json:"resource_type",yaml:"resource_type"`
-	Price        float64 `json:"price",yaml:"price"`
+	Address      string        `json:"address" yaml:"address"`
+	ResourceType string        `json:"resource_type" yaml:"resource_type"`
+	Estimate     EstimateTotal `json:"estimate" yaml:"estimate"`
+	Notes        []string      `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// NewEstimateTotal converts an hourly cost into monthly and yearly ones, where a year is twelve months of monthHours
+func NewEstimateTotal(hourly Range, monthHours float64) EstimateTotal {
+	monthly := hourly.Scale(monthHours)
+	return EstimateTotal{
+		HourlyCost:     hourly.Expected,
+		MonthlyCost:    monthly.Expected,
+		YearlyCost:     monthly.Expected * 12,
+		MinHourlyCost:  hourly.Min,
+		MinMonthlyCost: monthly.Min,
+		MinYearlyCost:  monthly.Min * 12,
+		MaxHourlyCost:  hourly.Max,
+		MaxMonthlyCost: monthly.Max,
+		MaxYearlyCost:  monthly.Max * 12,
+	}
 }
 
 /*
@@ -31,6 +57,7 @@ We're making our own statefile because the number of fields we need is very low
 
 type PlanFile struct {
 	ResourceChanges []ResourceChange `json:"resource_changes"`
+	Configuration   Configuration    `json:"configuration"`
 }
 
 type ResourceChange struct {
@@ -44,6 +71,30 @@ type Change struct {
 	After interface{} `json:"after"`
 }
 
+/*
+The configuration section of the plan is only used for the references between resources, since attributes such as ids
+are unknown until apply and can't be used to link resources together.
+*/
+
+type Configuration struct {
+	RootModule ConfigModule `json:"root_module"`
+}
+
+type ConfigModule struct {
+	Resources   []ConfigResource      `json:"resources"`
+	ModuleCalls map[string]ModuleCall `json:"module_calls"`
+}
+
+type ModuleCall struct {
+	Module ConfigModule `json:"module"`
+}
+
+type ConfigResource struct {
+	Address     string                 `json:"address"`
+	Type        string                 `json:"type"`
+	Expressions map[string]interface{} `json:"expressions"`
+}
+
 type AzurePricingApiResp struct {
 	BillingCurrency    string                `json:"BillingCurrency"`
 	CustomerEntityID   string                `json:"CustomerEntityId"`
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const WEEK_HOURS = 168.0
+
+type monthHoursKey struct{}
+
+// WithMonthHours sets the number of hours in a month used to convert between hourly and monthly prices
+func WithMonthHours(ctx context.Context, hours float64) context.Context {
+	return context.WithValue(ctx, monthHoursKey{}, hours)
+}
+
+// MonthHours returns the month length set with WithMonthHours, defaulting to MONTH_HOURS
+func MonthHours(ctx context.Context) float64 {
+	if hours, ok := ctx.Value(monthHoursKey{}).(float64); ok && hours > 0 {
+		return hours
+	}
+	return MONTH_HOURS
+}
+
+/*
+Uptime profiles let users say how long compute actually runs, for things like dev VMs that are only started during
+working hours.  A profile applies to a resource by its address, its tags or its type, in that order of precedence,
+and either gives the hours per month directly or a weekly schedule.
+*/
+type UptimeProfiles struct {
+	Profiles []UptimeProfile `json:"profiles" yaml:"profiles"`
+}
+
+type UptimeProfile struct {
+	Address       string            `json:"address,omitempty" yaml:"address,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ResourceType  string            `json:"resource_type,omitempty" yaml:"resource_type,omitempty"`
+	HoursPerMonth *float64          `json:"hours_per_month,omitempty" yaml:"hours_per_month,omitempty"`
+	Schedule      *UptimeSchedule   `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+// UptimeSchedule runs from Start to End (24 hour "08:00" or "0800" times) on each of Days, or every day if Days is empty.
+// Days are named in full or by their first three letters, e.g. Monday or Mon.
+type UptimeSchedule struct {
+	Days  []string `json:"days,omitempty" yaml:"days,omitempty"`
+	Start string   `json:"start" yaml:"start"`
+	End   string   `json:"end" yaml:"end"`
+}
+
+// Validate checks that every profile gives its uptime in a way that can be read, so that a bad schedule is reported
+// before anything is priced
+func (u UptimeProfiles) Validate() error {
+	for _, profile := range u.Profiles {
+		if _, err := profile.Fraction(MONTH_HOURS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fraction returns the share of the month that a resource with this profile is running
+func (p UptimeProfile) Fraction(monthHours float64) (float64, error) {
+	if p.HoursPerMonth != nil {
+		return clampFraction(*p.HoursPerMonth / monthHours), nil
+	}
+	if p.Schedule != nil {
+		weekly, err := p.Schedule.HoursPerWeek()
+		if err != nil {
+			return 0, err
+		}
+		return clampFraction(weekly / WEEK_HOURS), nil
+	}
+	return 0, fmt.Errorf("uptime profile for %q needs either hours_per_month or a schedule", p.target())
+}
+
+func (s UptimeSchedule) HoursPerWeek() (float64, error) {
+	start, err := ParseClockHours(s.Start)
+	if err != nil {
+		return 0, err
+	}
+	end, err := ParseClockHours(s.End)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.validateDays(); err != nil {
+		return 0, err
+	}
+	days := float64(len(s.Days))
+	if days == 0 {
+		days = 7
+	}
+	return days * DailyHours(start, end), nil
+}
+
+// validateDays rejects day names that aren't a day of the week, e.g. "Monday" or "Mon", and days given more than once,
+// either of which would throw off the hours the schedule runs for
+func (s UptimeSchedule) validateDays() error {
+	seen := map[time.Weekday]bool{}
+	for _, name := range s.Days {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return fmt.Errorf("%q is not a day of the week such as Monday", name)
+		}
+		if seen[day] {
+			return fmt.Errorf("%s is in the schedule's days more than once", day)
+		}
+		seen[day] = true
+	}
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// DailyHours is the number of hours between two times of day, running past midnight when end is before start
+func DailyHours(start, end float64) float64 {
+	if end <= start {
+		end += 24
+	}
+	return end - start
+}
+
+// ParseClockHours converts a 24 hour time such as "18:30" or "1830" into hours since midnight
+func ParseClockHours(clock string) (float64, error) {
+	digits := strings.Replace(strings.TrimSpace(clock), ":", "", 1)
+	if len(digits) != 4 {
+		return 0, fmt.Errorf("%q is not a 24 hour time such as 18:30", clock)
+	}
+	hours, err := strconv.Atoi(digits[:2])
+	if err != nil || hours > 23 {
+		return 0, fmt.Errorf("%q is not a 24 hour time such as 18:30", clock)
+	}
+	minutes, err := strconv.Atoi(digits[2:])
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("%q is not a 24 hour time such as 18:30", clock)
+	}
+	return float64(hours) + float64(minutes)/60.0, nil
+}
+
+func (p UptimeProfile) target() string {
+	switch {
+	case p.Address != "":
+		return p.Address
+	case p.ResourceType != "":
+		return p.ResourceType
+	default:
+		return fmt.Sprintf("%v", p.Tags)
+	}
+}
+
+func clampFraction(f float64) float64 {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+A usage profile supplies the expected monthly quantities of consumption based charges, such as GB stored, GB of egress
+or transactions, which can't be read from a plan.  Quantities can be given for every resource of a type and overridden
+for a single resource by its address.
+*/
+type UsageProfile struct {
+	ResourceTypes map[string]Usage `json:"resource_types,omitempty" yaml:"resource_types,omitempty"`
+	Resources     map[string]Usage `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+// Usage maps a usage key, such as "storage_gb", to its expected monthly quantity
+type Usage map[string]float64
+
+// For returns the usage of a resource, where the quantities given for its addresses (in increasing order of
+// precedence) override those given for its type
+func (u UsageProfile) For(resourceType string, addresses ...string) Usage {
+	usage := Usage{}
+	for key, quantity := range u.ResourceTypes[resourceType] {
+		usage[key] = quantity
+	}
+	for _, address := range addresses {
+		for key, quantity := range u.Resources[address] {
+			usage[key] = quantity
+		}
+	}
+	return usage
+}
+
+// Validate rejects negative quantities, which no usage can have
+func (u UsageProfile) Validate() error {
+	for _, byTarget := range []map[string]Usage{u.ResourceTypes, u.Resources} {
+		for target, usage := range byTarget {
+			for key, quantity := range usage {
+				if quantity < 0 {
+					return fmt.Errorf("the %s usage of %q can't be negative, got %g", key, target, quantity)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (u Usage) Get(key string) (float64, bool) {
+	quantity, ok := u[key]
+	return quantity, ok
+}
+
+// EstimateRequest is an API request body that carries usage and uptime profiles along with the plan.  A body that is
+// only the plan is still accepted.
+type EstimateRequest struct {
+	Plan   json.RawMessage `json:"plan"`
+	Usage  UsageProfile    `json:"usage"`
+	Uptime UptimeProfiles  `json:"uptime"`
+}
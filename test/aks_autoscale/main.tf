@@ -0,0 +1,40 @@
+provider "azurerm" {
+  features {}
+  version = "=2.37.0"
+}
+
+resource "azurerm_resource_group" "example" {
+  name     = "example-resources"
+  location = "West Europe"
+}
+
+resource "azurerm_kubernetes_cluster" "example" {
+  name                = "example-aks1"
+  location            = azurerm_resource_group.example.location
+  resource_group_name = azurerm_resource_group.example.name
+  dns_prefix          = "exampleaks1"
+
+  default_node_pool {
+    name                = "default"
+    vm_size             = "Standard_D2s_v3"
+    enable_auto_scaling = true
+    node_count          = 2
+    min_count           = 1
+    max_count           = 5
+  }
+
+  identity {
+    type = "SystemAssigned"
+  }
+}
+
+resource "azurerm_kubernetes_cluster_node_pool" "windows" {
+  name                  = "win"
+  kubernetes_cluster_id = azurerm_kubernetes_cluster.example.id
+  vm_size               = "Standard_D4s_v3"
+  os_type               = "Windows"
+  os_disk_type          = "Ephemeral"
+  enable_auto_scaling   = true
+  min_count             = 2
+  max_count             = 10
+}
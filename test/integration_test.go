@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
-	"github.com/zparnold/azure-terraform-cost-estimator/common/types"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -69,7 +69,8 @@ func TestAksClusterWith5Nodes(t *testing.T) {
 		t.Error(err)
 	}
 	expectedPrice := (0.136 * 5) + 0.10 // aks control plane paid sku
-	assert.Equal(t, expectedPrice, resp.TotalEstimate.HourlyCost)
+	expectedPrice += 5 * 9.60 / 730     // a 128GB E10 managed OS disk per node
+	assert.InDelta(t, expectedPrice, resp.TotalEstimate.HourlyCost, 0.001)
 }
 
 func TestVmss(t *testing.T) {
@@ -82,8 +83,8 @@ func TestVmss(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	expectedPrice := (0.099 * 2)
-	assert.Equal(t, expectedPrice, resp.TotalEstimate.HourlyCost)
+	expectedPrice := (0.099 * 2) + 0.0036 // two instances and a Basic static public IP in front of a free Basic load balancer
+	assert.InDelta(t, expectedPrice, resp.TotalEstimate.HourlyCost, 0.0001)
 }
 
 func TestLegacyVmResource(t *testing.T) {
@@ -96,8 +97,8 @@ func TestLegacyVmResource(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	expectedPrice := 0.198
-	assert.Equal(t, expectedPrice, resp.TotalEstimate.HourlyCost)
+	expectedPrice := 0.198 + (2 * 1.536 / 730) // compute plus two S4 standard OS disks
+	assert.InDelta(t, expectedPrice, resp.TotalEstimate.HourlyCost, 0.0001)
 }
 func TestWindowsVmss(t *testing.T) {
 
@@ -4,8 +4,8 @@ import (
 	"context"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
-	"github.com/zparnold/azure-terraform-cost-estimator/common/pricers/azure"
-	"github.com/zparnold/azure-terraform-cost-estimator/common/types"
+	"github.com/zparnold/terraform-cost-estimator/common/pricers/azure"
+	"github.com/zparnold/terraform-cost-estimator/common/types"
 	"os"
 	"testing"
 )
@@ -20,8 +20,7 @@ func TestLinuxVmConsumption(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	price, unsupportedResources, unestimateableResources, err := azure.PricePlanFile(ctx, jsonPlan, azure.Consumption)
-	resp, err := wrapResponse(price, unsupportedResources, unestimateableResources)
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
 
 	if err != nil {
 		t.Error(err)
@@ -40,16 +39,14 @@ func TestLinuxVmReservation(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	price, unsupportedResources, unestimateableResources, err := azure.PricePlanFile(ctx, jsonPlan, azure.Reservation1Yr)
-	resp, err := wrapResponse(price, unsupportedResources, unestimateableResources)
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Reservation1Yr})
 
 	if err != nil {
 		t.Error(err)
 	}
 	assert.InDelta(t, 0.0827, resp.TotalEstimate.HourlyCost, 0.0001)
 
-	price, unsupportedResources, unestimateableResources, err = azure.PricePlanFile(ctx, jsonPlan, azure.Reservation3Yr)
-	resp, err = wrapResponse(price, unsupportedResources, unestimateableResources)
+	resp, err = azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Reservation3Yr})
 
 	if err != nil {
 		t.Error(err)
@@ -57,12 +54,270 @@ func TestLinuxVmReservation(t *testing.T) {
 	assert.InDelta(t, 0.0565, resp.TotalEstimate.HourlyCost, 0.0001)
 }
 
-func wrapResponse(price float64, unsupportedResources []string, unestimateableResources []string) (types.ApiResp, error) {
-	var r types.ApiResp
-	r.TotalEstimate.HourlyCost = price
-	r.TotalEstimate.MonthlyCost = price * types.MONTH_HOURS
-	r.TotalEstimate.YearlyCost = price * types.YEAR_HOURS
-	r.UnsupportedResources = unsupportedResources
-	r.UnestimateableResources = unestimateableResources
-	return r, nil
+func TestAksAutoscalingRange(t *testing.T) {
+	// disable xray when testing locally -otherwise you'll get an x-ray 'segment' error
+	_ = os.Setenv("AWS_XRAY_SDK_DISABLED", "true")
+
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./aks_autoscale/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	ctx := context.Background()
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, resp.UnsupportedResources)
+	assert.Len(t, resp.PriceItems, 3)
+	assert.Less(t, resp.TotalEstimate.MinHourlyCost, resp.TotalEstimate.HourlyCost)
+	assert.Less(t, resp.TotalEstimate.HourlyCost, resp.TotalEstimate.MaxHourlyCost)
+}
+
+func TestVmssAutoscaleSettingRange(t *testing.T) {
+	// disable xray when testing locally -otherwise you'll get an x-ray 'segment' error
+	_ = os.Setenv("AWS_XRAY_SDK_DISABLED", "true")
+
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./vmss_autoscale/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	ctx := context.Background()
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, resp.UnsupportedResources)
+	// 1, 2 and 10 Standard_F2 instances rather than the single instance in the scale set itself
+	assert.InDelta(t, 0.114, resp.TotalEstimate.MinHourlyCost, 0.0001)
+	assert.InDelta(t, 0.114*2, resp.TotalEstimate.HourlyCost, 0.0001)
+	assert.InDelta(t, 0.114*10, resp.TotalEstimate.MaxHourlyCost, 0.0001)
+}
+
+func TestVmShutdownSchedule(t *testing.T) {
+	// disable xray when testing locally -otherwise you'll get an x-ray 'segment' error
+	_ = os.Setenv("AWS_XRAY_SDK_DISABLED", "true")
+
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./vm_shutdown/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	ctx := context.Background()
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
+
+	if err != nil {
+		t.Error(err)
+	}
+	// started at 09:00 and shut down at 19:00 on weekdays, or every day at most
+	assert.InDelta(t, 0.114*50/168, resp.TotalEstimate.HourlyCost, 0.0001)
+	assert.InDelta(t, 0.114*70/168, resp.TotalEstimate.MaxHourlyCost, 0.0001)
+
+	// an uptime profile for the VM takes precedence over its shutdown schedule
+	hours := 100.0
+	resp, err = azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{
+		Scheme:     azure.Consumption,
+		MonthHours: 744,
+		Uptime: types.UptimeProfiles{Profiles: []types.UptimeProfile{
+			{ResourceType: "azurerm_linux_virtual_machine", HoursPerMonth: &hours},
+		}},
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.InDelta(t, 0.114*100, resp.TotalEstimate.MonthlyCost, 0.01)
+}
+
+func TestUptimeProfileFraction(t *testing.T) {
+	workdays := types.UptimeProfile{Schedule: &types.UptimeSchedule{
+		Days:  []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+		Start: "08:00",
+		End:   "18:00",
+	}}
+	fraction, err := workdays.Fraction(types.MONTH_HOURS)
+	assert.NoError(t, err)
+	assert.InDelta(t, 50.0/168.0, fraction, 0.0001)
+
+	overnight := types.UptimeProfile{Schedule: &types.UptimeSchedule{Start: "2200", End: "0600"}}
+	fraction, err = overnight.Fraction(types.MONTH_HOURS)
+	assert.NoError(t, err)
+	assert.InDelta(t, 8.0/24.0, fraction, 0.0001)
+
+	hours := 365.0
+	fraction, err = types.UptimeProfile{HoursPerMonth: &hours}.Fraction(types.MONTH_HOURS)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, fraction, 0.0001)
+
+	_, err = types.UptimeProfile{Schedule: &types.UptimeSchedule{Start: "8am", End: "18:00"}}.Fraction(types.MONTH_HOURS)
+	assert.Error(t, err)
+	_, err = types.UptimeProfile{Address: "azurerm_linux_virtual_machine.example"}.Fraction(types.MONTH_HOURS)
+	assert.Error(t, err)
+
+	fraction, err = types.UptimeProfile{Schedule: &types.UptimeSchedule{Days: []string{"sat", "Sunday"}, Start: "10:00", End: "14:00"}}.Fraction(types.MONTH_HOURS)
+	assert.NoError(t, err)
+	assert.InDelta(t, 8.0/168.0, fraction, 0.0001)
+	for _, days := range [][]string{{"Monday", "Munday"}, {"Monday", "monday"}, {"Mon", "Monday"}} {
+		_, err = types.UptimeProfile{Schedule: &types.UptimeSchedule{Days: days, Start: "08:00", End: "18:00"}}.Fraction(types.MONTH_HOURS)
+		assert.Error(t, err, "%v", days)
+	}
+}
+
+func TestUsageTemplate(t *testing.T) {
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./linuxvmtest/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	template, err := azure.UsageTemplate(jsonPlan)
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, []string{"peering_egress_gb", "peering_ingress_gb", "internet_egress_gb"}, template["azurerm_virtual_network.example"])
+	assert.NotContains(t, template, "azurerm_linux_virtual_machine.example")
+}
+
+func TestStorageAccountUsage(t *testing.T) {
+	// disable xray when testing locally -otherwise you'll get an x-ray 'segment' error
+	_ = os.Setenv("AWS_XRAY_SDK_DISABLED", "true")
+
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./storage/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	ctx := context.Background()
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, resp.UnsupportedResources)
+	assert.Len(t, resp.PriceItems, 3)
+	// without usage the account is free but says why, and the share could be anywhere up to its quota
+	assert.Equal(t, []string{"resource groups are free"}, resp.PriceItems[0].Notes)
+	assert.Equal(t, 0.0, resp.PriceItems[1].Estimate.MaxMonthlyCost)
+	assert.NotEmpty(t, resp.PriceItems[1].Notes)
+	assert.Equal(t, 0.0, resp.PriceItems[2].Estimate.MinMonthlyCost)
+	assert.Greater(t, resp.PriceItems[2].Estimate.MaxMonthlyCost, 0.0)
+
+	resp, err = azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{
+		Scheme: azure.Consumption,
+		Usage: types.UsageProfile{Resources: map[string]types.Usage{
+			"azurerm_storage_account.example": {"storage_gb": 1000, "write_operations": 1000000, "read_operations": 10000000},
+		}},
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, resp.PriceItems[1].Notes)
+	assert.Greater(t, resp.PriceItems[1].Estimate.MonthlyCost, 0.0)
+}
+
+func TestAppServicePlan(t *testing.T) {
+	// disable xray when testing locally -otherwise you'll get an x-ray 'segment' error
+	_ = os.Setenv("AWS_XRAY_SDK_DISABLED", "true")
+
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./appservice/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	ctx := context.Background()
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, resp.UnsupportedResources)
+	assert.Len(t, resp.PriceItems, 3)
+	// the app costs nothing on top of the two instances of the plan it runs on
+	assert.Equal(t, "azurerm_app_service.example", resp.PriceItems[0].Address)
+	assert.Equal(t, 0.0, resp.PriceItems[0].Estimate.MonthlyCost)
+	assert.Equal(t, []string{"covered by the cost of azurerm_app_service_plan.example"}, resp.PriceItems[0].Notes)
+	assert.Greater(t, resp.PriceItems[1].Estimate.MonthlyCost, 0.0)
+}
+
+func TestSqlServerlessDatabase(t *testing.T) {
+	// disable xray when testing locally -otherwise you'll get an x-ray 'segment' error
+	_ = os.Setenv("AWS_XRAY_SDK_DISABLED", "true")
+
+	jsonPlan := terraform.InitAndPlanAndShow(t, &terraform.Options{
+		TerraformDir: "./sql/",
+		PlanFilePath: "./tfplan.out",
+	})
+
+	ctx := context.Background()
+	resp, err := azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{Scheme: azure.Consumption})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Empty(t, resp.UnsupportedResources)
+	assert.Len(t, resp.PriceItems, 3)
+	assert.Empty(t, resp.UnestimateableResources)
+	// paused all month at best, and running all month at its 2 vCores at worst
+	assert.Less(t, resp.TotalEstimate.MinMonthlyCost, resp.TotalEstimate.MonthlyCost)
+	assert.Less(t, resp.TotalEstimate.MonthlyCost, resp.TotalEstimate.MaxMonthlyCost)
+
+	resp, err = azure.PricePlanFile(ctx, jsonPlan, azure.PricingOptions{
+		Scheme: azure.Consumption,
+		Usage: types.UsageProfile{ResourceTypes: map[string]types.Usage{
+			"azurerm_mssql_database": {"active_hours": 200},
+		}},
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Less(t, resp.TotalEstimate.MinMonthlyCost, resp.TotalEstimate.MaxMonthlyCost)
+	assert.Greater(t, resp.TotalEstimate.MinMonthlyCost, 0.0)
+}
+
+func TestProfilesValidate(t *testing.T) {
+	assert.NoError(t, types.UsageProfile{Resources: map[string]types.Usage{"azurerm_storage_account.example": {"storage_gb": 0}}}.Validate())
+	assert.Error(t, types.UsageProfile{ResourceTypes: map[string]types.Usage{"azurerm_storage_account": {"storage_gb": -1}}}.Validate())
+
+	hours := 100.0
+	assert.NoError(t, types.UptimeProfiles{Profiles: []types.UptimeProfile{{ResourceType: "azurerm_linux_virtual_machine", HoursPerMonth: &hours}}}.Validate())
+	assert.Error(t, types.UptimeProfiles{Profiles: []types.UptimeProfile{{ResourceType: "azurerm_linux_virtual_machine"}}}.Validate())
+	assert.Error(t, types.UptimeProfiles{Profiles: []types.UptimeProfile{
+		{ResourceType: "azurerm_linux_virtual_machine", Schedule: &types.UptimeSchedule{Days: []string{"Funday"}, Start: "09:00", End: "17:00"}},
+	}}.Validate())
+}
+
+func TestUsageProfileFor(t *testing.T) {
+	profile := types.UsageProfile{
+		ResourceTypes: map[string]types.Usage{
+			"azurerm_virtual_network": {"internet_egress_gb": 100, "peering_egress_gb": 10},
+		},
+		Resources: map[string]types.Usage{
+			"module.hub.azurerm_virtual_network.main":    {"internet_egress_gb": 500},
+			"module.hub.azurerm_virtual_network.main[1]": {"internet_egress_gb": 1000},
+		},
+	}
+
+	usage := profile.For("azurerm_virtual_network", "module.hub.azurerm_virtual_network.main", "module.hub.azurerm_virtual_network.main[0]")
+	assert.Equal(t, types.Usage{"internet_egress_gb": 500, "peering_egress_gb": 10}, usage)
+
+	usage = profile.For("azurerm_virtual_network", "module.hub.azurerm_virtual_network.main", "module.hub.azurerm_virtual_network.main[1]")
+	assert.Equal(t, types.Usage{"internet_egress_gb": 1000, "peering_egress_gb": 10}, usage)
+
+	_, ok := profile.For("azurerm_subnet", "azurerm_subnet.internal").Get("internet_egress_gb")
+	assert.False(t, ok)
+}
+
+func TestEstimateTotalRange(t *testing.T) {
+	total := types.NewEstimateTotal(types.FixedRange(0.10).Add(types.Range{Min: 0.20, Expected: 0.40, Max: 1.00}), types.MONTH_HOURS)
+
+	assert.InDelta(t, 0.30, total.MinHourlyCost, 0.0001)
+	assert.InDelta(t, 0.50, total.HourlyCost, 0.0001)
+	assert.InDelta(t, 1.10, total.MaxHourlyCost, 0.0001)
+	assert.InDelta(t, 0.50*types.MONTH_HOURS, total.MonthlyCost, 0.0001)
+	assert.InDelta(t, 1.10*types.MONTH_HOURS*12, total.MaxYearlyCost, 0.0001)
 }